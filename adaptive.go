@@ -0,0 +1,202 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AdaptivePolicy is configuring the adaptive concurrency of the workers of a stream, see AdaptiveWorkersOpt.
+// The concurrency limit is adapted with AIMD (additive increase, multiplicative decrease): it is increased by 1
+// after limit successful Mapper calls, and multiplied by Backoff when a Mapper call failed or its latency
+// exceeded the lowest observed latency by the factor Tolerance.
+type AdaptivePolicy struct {
+	// Min is the lowest concurrency limit, which is also the initial one.
+	Min int
+	// Max is the highest concurrency limit, which is the number of worker goroutines started.
+	Max int
+	// Tolerance is the factor of the lowest observed latency above which a Mapper call is signaling
+	// an overload (default: 2).
+	Tolerance float64
+	// Backoff is the factor the limit is multiplied with on overload, between 0 and 1 (default: 0.75).
+	Backoff float64
+	// OnLimit is called with the new limit after every change, e.g. for exporting it as a metric.
+	OnLimit func(limit int)
+}
+
+// concurrencyLimit is a semaphore with a dynamic limit for the Mapper calls of the workers of a stream,
+// which is adapted to the observed latency and errors if an AdaptivePolicy is set.
+type concurrencyLimit struct {
+	mu    sync.Mutex
+	limit int
+	// max is the highest limit which can be set, e.g. the number of lanes in keyed mode - 0 for no max.
+	max      int
+	inFlight int
+	// wake is closed and replaced when a Mapper call may be able to acquire a slot.
+	wake chan struct{}
+
+	policy *AdaptivePolicy
+	// minLatency is the lowest latency observed, drifting up slowly to follow a changing baseline.
+	minLatency time.Duration
+	// successes are the successful calls since the last increase of the limit.
+	successes int
+	// calls are the calls since the last decrease of the limit.
+	calls int
+}
+
+// newConcurrencyLimit is returning a concurrencyLimit for the AdaptivePolicy of the given config, or for
+// changing the number of workers with a Control. It returns nil if neither is configured.
+func newConcurrencyLimit(cfg *streamConf) *concurrencyLimit {
+	if cfg.Adaptive == nil && cfg.Control == nil {
+		return nil
+	}
+
+	limit := cfg.Workers
+	if cfg.Adaptive != nil {
+		limit = cfg.Adaptive.Min
+	}
+
+	// the number of lanes is fixed in keyed mode
+	maxLimit := 0
+	if cfg.Lane != nil {
+		maxLimit = cfg.Workers
+	}
+
+	return &concurrencyLimit{
+		limit:  limit,
+		max:    maxLimit,
+		wake:   make(chan struct{}),
+		policy: cfg.Adaptive,
+	}
+}
+
+// acquire is blocking until a Mapper call is allowed by the limit. It returns false if the context was done before.
+func (c *concurrencyLimit) acquire(ctx context.Context) bool {
+	if c == nil {
+		return true
+	}
+
+	for {
+		c.mu.Lock()
+		if c.inFlight < c.limit {
+			c.inFlight++
+			c.mu.Unlock()
+			return true
+		}
+		wake := c.wake
+		c.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// release is reporting a finished Mapper call with its latency and error, adapting the limit.
+func (c *concurrencyLimit) release(latency time.Duration, err error) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.inFlight--
+	changed := c.adapt(latency, err)
+	limit := c.limit
+	close(c.wake)
+	c.wake = make(chan struct{})
+	c.mu.Unlock()
+
+	if changed && c.policy.OnLimit != nil {
+		c.policy.OnLimit(limit)
+	}
+}
+
+// current is returning the current limit.
+func (c *concurrencyLimit) current() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.limit
+}
+
+// set is changing the limit, kept between Min and Max of the AdaptivePolicy if set and below the max of
+// the limit. It returns the new limit.
+func (c *concurrencyLimit) set(limit int) int {
+	c.mu.Lock()
+	if c.policy != nil {
+		limit = min(c.policy.Max, max(c.policy.Min, limit))
+	}
+	if c.max > 0 {
+		limit = min(c.max, limit)
+	}
+	changed := limit != c.limit
+	c.limit = limit
+	close(c.wake)
+	c.wake = make(chan struct{})
+	c.mu.Unlock()
+
+	if changed && c.policy != nil && c.policy.OnLimit != nil {
+		c.policy.OnLimit(limit)
+	}
+
+	return limit
+}
+
+// adapt is adapting the limit to a finished Mapper call, returning true if it changed - the mutex needs to be held.
+func (c *concurrencyLimit) adapt(latency time.Duration, err error) bool {
+	if c.policy == nil {
+		return false
+	}
+
+	tolerance := c.policy.Tolerance
+	if tolerance == 0 {
+		tolerance = 2
+	}
+	backoff := c.policy.Backoff
+	if backoff == 0 {
+		backoff = 0.75
+	}
+
+	overload := (err != nil && !errors.Is(err, ErrSkip)) ||
+		(c.minLatency > 0 && float64(latency) > tolerance*float64(c.minLatency))
+
+	if c.minLatency == 0 || latency < c.minLatency {
+		c.minLatency = latency
+	} else {
+		c.minLatency += (latency - c.minLatency) / 100
+	}
+
+	c.calls++
+	old := c.limit
+
+	if overload {
+		c.successes = 0
+		// the calls started before the last decrease are not reducing the limit again
+		if c.calls >= c.limit {
+			c.limit = max(c.policy.Min, int(float64(c.limit)*backoff))
+			c.calls = 0
+		}
+	} else {
+		c.successes++
+		if c.successes >= c.limit {
+			c.limit = min(c.policy.Max, c.limit+1)
+			c.successes = 0
+		}
+	}
+
+	return c.limit != old
+}
+
+// validate is panicking if the policy is invalid.
+func (a *AdaptivePolicy) validate() {
+	if a.Min < 1 || a.Max < a.Min {
+		panic(fmt.Sprintf("adaptive workers min: %d, max: %d - need at least 1 worker and max >= min", a.Min, a.Max))
+	}
+	if a.Backoff < 0 || a.Backoff >= 1 {
+		panic(fmt.Sprintf("adaptive workers backoff: %f - needs to be between 0 and 1", a.Backoff))
+	}
+}
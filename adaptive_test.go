@@ -0,0 +1,114 @@
+package iter
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+
+	limits := []int{}
+	policy := &AdaptivePolicy{Min: 1, Max: 4, OnLimit: func(limit int) { limits = append(limits, limit) }}
+	limit := newConcurrencyLimit(&streamConf{Adaptive: policy})
+
+	call := func(latency time.Duration, err error) {
+		if !limit.acquire(context.Background()) {
+			t.Fatal("Expected to acquire a slot")
+		}
+		limit.release(latency, err)
+	}
+
+	// the limit is increased by 1 after limit successful calls, up to the max
+	for i := 0; i < 12; i++ {
+		call(time.Millisecond, nil)
+	}
+
+	// errors and high latencies are decreasing the limit, but only once per limit calls
+	call(time.Millisecond, errFive)
+	call(time.Millisecond, errFive)
+	call(time.Millisecond, nil)
+	call(10*time.Millisecond, nil)
+
+	if want, got := []int{2, 3, 4, 3, 2}, limits; !slices.Equal(want, got) {
+		t.Fatalf("Expected limits %v, got %v", want, got)
+	}
+}
+
+func TestAdaptiveWorkers(t *testing.T) {
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	mapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		return squareMapper(ctx, input)
+	}
+
+	for testnr, parms := range testCases {
+
+		maxInFlight = 0
+		stream := NewStream(context.Background(), mapper, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers),
+			AdaptiveWorkersOpt(AdaptivePolicy{Min: 1, Max: 3}))
+
+		items, err := Collect(context.Background(), stream(&testIter{list: list}))
+		if err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		if want, got := len(list), len(items); want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+
+		if maxInFlight > 3 {
+			t.Fatalf("test %d: Expected at most 3 concurrent Mapper calls, got %d", testnr, maxInFlight)
+		}
+	}
+}
+
+func TestAdaptiveWorkersControl(t *testing.T) {
+
+	nop := func(_ context.Context, item int) (int, error) { return item, nil }
+
+	items := make([]int, 100)
+
+	// the current limit is read with the Control, the high tolerance is keeping it from decreasing
+	ctrl := NewControl()
+	iter := NewStream(context.Background(), nop, ControlOpt(ctrl),
+		AdaptiveWorkersOpt(AdaptivePolicy{Min: 2, Max: 4, Tolerance: 1e6}))(FromSlice(items))
+	defer iter.Close()
+
+	limits := map[int]bool{}
+	for {
+		_, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		limits[ctrl.Workers()] = true
+	}
+
+	if !limits[4] || len(limits) > 3 || limits[0] || limits[1] {
+		t.Fatalf("Expected limits from 2 up to 4, got %v", limits)
+	}
+
+	// without running streams, the initial limit of the stream is returned
+	if want, got := 2, ctrl.Workers(); want != got {
+		t.Fatalf("Expected %d workers, got %d", want, got)
+	}
+}
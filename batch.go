@@ -0,0 +1,214 @@
+package iter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type batchConf struct {
+	MaxSize  int
+	MaxBytes int
+	SizeOf   func(item interface{}) int
+	Linger   time.Duration
+}
+
+// newBatchConf is creating a default batch config and applies the given BatchOpts.
+func newBatchConf(opts ...BatchOpt) *batchConf {
+	conf := &batchConf{
+		MaxSize:  100,
+		MaxBytes: 0,
+		SizeOf:   nil,
+		Linger:   0,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// BatchOpt is a functional option type for Batch.
+type BatchOpt func(conf *batchConf)
+
+// BatchSizeOpt is a functional option setting the max amount of items in a batch (default: 100).
+func BatchSizeOpt(size int) BatchOpt {
+	if size < 1 {
+		panic(fmt.Sprintf("batch size: %d - need at least 1 item per batch", size))
+	}
+	return func(conf *batchConf) {
+		conf.MaxSize = size
+	}
+}
+
+// BatchBytesOpt is a functional option setting the max size of a batch in bytes, as estimated by the
+// given sizeOf func for every item (default: 0 - no limit). An item exceeding the limit on its own is
+// emitted as a single item batch.
+func BatchBytesOpt[T any](maxBytes int, sizeOf func(item T) int) BatchOpt {
+	return func(conf *batchConf) {
+		conf.MaxBytes = maxBytes
+		conf.SizeOf = func(item interface{}) int {
+			return sizeOf(item.(T))
+		}
+	}
+}
+
+// BatchLingerOpt is a functional option setting the max time to wait for a batch to be filled after its
+// first item arrived, before emitting it partially filled (default: 0 - wait until the batch is full).
+func BatchLingerOpt(d time.Duration) BatchOpt {
+	return func(conf *batchConf) {
+		conf.Linger = d
+	}
+}
+
+// Batch is returning a TypedIterator grouping the items of the given iterator into batches, which are
+// emitted when they are full or lingered long enough, see BatchOpt. A partially filled batch is emitted
+// when the input iterator is exhausted, which includes an input stream being closed by another goroutine.
+// Closing the returned iterator is discarding the pending batch together with the buffered results, like
+// Close of a stream - close the input instead for getting the partial batch.
+// Errors of the input iterator are passed through after emitting the pending batch. The input iterator is
+// closed when the returned iterator is closed or finished.
+// The returned iterator can be used as input of a stream applying a mapper func to the batches with
+// multiple workers.
+func Batch[T any](ctx context.Context, it TypedIterator[T], opts ...BatchOpt) TypedIterator[[]T] {
+
+	cfg := newBatchConf(opts...)
+
+	batchChan := make(chan []T)
+	errChan := make(chan error)
+	done := make(chan struct{})
+
+	myCtx, cancel := context.WithCancelCause(ctx)
+
+	iter := newStreamIterator(batchChan, errChan, func() { cancel(ErrClosed) }, done)
+
+	// the reader goroutine is pulling the items from the input iterator, so the batcher is not
+	// blocked by the input while waiting for the linger time to pass
+	items := readAll(myCtx, it, newResult[T])
+
+	go func() {
+		defer close(done)
+		defer close(batchChan)
+
+		// closing the input is unblocking the reader, which is exiting before the input is released
+		defer func() {
+			it.Close()
+			for range items {
+			}
+		}()
+
+		if completed := batch(myCtx, cfg, items, batchChan, errChan); completed {
+			iter.finish(StateCompleted, nil)
+		} else {
+			iter.finish(StateCanceled, context.Cause(myCtx))
+		}
+	}()
+
+	return iter
+}
+
+// batch is collecting the items into batches until the items channel is closed (returning true)
+// or the context is done (returning false).
+func batch[T any](ctx context.Context, cfg *batchConf, items <-chan result[T], batchChan chan<- []T, errChan chan<- error) bool {
+
+	var current []T
+	var bytes int
+
+	timer := time.NewTimer(0)
+	timer.Stop()
+	var linger <-chan time.Time
+
+	flush := func() bool {
+		timer.Stop()
+		linger = nil
+
+		if len(current) == 0 {
+			return true
+		}
+
+		select {
+		case batchChan <- current:
+		case <-ctx.Done():
+			return false
+		}
+
+		current = nil
+		bytes = 0
+		return true
+	}
+
+	for {
+		select {
+		case res, ok := <-items:
+			if !ok {
+				return flush()
+			}
+
+			if res.err != nil {
+				if !flush() {
+					return false
+				}
+				select {
+				case errChan <- res.err:
+				case <-ctx.Done():
+					return false
+				}
+				continue
+			}
+
+			size := 0
+			if cfg.SizeOf != nil {
+				size = cfg.SizeOf(res.item)
+			}
+
+			// the item would exceed the max bytes, so it's going into the next batch
+			if cfg.MaxBytes > 0 && len(current) > 0 && bytes+size > cfg.MaxBytes {
+				if !flush() {
+					return false
+				}
+			}
+
+			current = append(current, res.item)
+			bytes += size
+
+			if len(current) == 1 && cfg.Linger > 0 {
+				timer.Reset(cfg.Linger)
+				linger = timer.C
+			}
+
+			if len(current) >= cfg.MaxSize || (cfg.MaxBytes > 0 && bytes >= cfg.MaxBytes) {
+				if !flush() {
+					return false
+				}
+			}
+
+		case <-linger:
+			linger = nil
+			if !flush() {
+				return false
+			}
+
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// Unbatch is returning a TypedIterator streaming the items of the batches returned by the given
+// iterator one by one, which is the inverse of Batch.
+func Unbatch[T any](it TypedIterator[[]T]) TypedIterator[T] {
+	return &flatIterator[T]{outer: &sliceIterators[T]{it}}
+}
+
+// sliceIterators is implementing a TypedIterator of iterators over the slices returned by the given iterator.
+type sliceIterators[T any] struct {
+	TypedIterator[[]T]
+}
+
+// Next is returning an iterator over the next slice.
+func (i *sliceIterators[T]) Next() (TypedIterator[T], error) {
+	items, err := i.TypedIterator.Next()
+	if err != nil {
+		return nil, err
+	}
+	return FromSlice(items), nil
+}
@@ -0,0 +1,189 @@
+package iter
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+var ints = []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+// collectBatches is reading all batches of the given iterator.
+func collectBatches(t *testing.T, it TypedIterator[[]int]) [][]int {
+	defer it.Close()
+
+	batches := [][]int{}
+	for {
+		batch, err := it.Next()
+		if err == io.EOF {
+			return batches
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, batch)
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+
+	batches := collectBatches(t, Batch(context.Background(), FromSlice(ints), BatchSizeOpt(4)))
+
+	if want, got := [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9}}, batches; !slices.EqualFunc(want, got, slices.Equal) {
+		t.Fatalf("Expected batches %v, got %v", want, got)
+	}
+}
+
+func TestBatchBytes(t *testing.T) {
+
+	sizeOf := func(item int) int { return item }
+
+	batches := collectBatches(t, Batch(context.Background(), FromSlice(ints), BatchBytesOpt(10, sizeOf)))
+
+	if want, got := [][]int{{1, 2, 3, 4}, {5}, {6}, {7}, {8}, {9}}, batches; !slices.EqualFunc(want, got, slices.Equal) {
+		t.Fatalf("Expected batches %v, got %v", want, got)
+	}
+}
+
+func TestBatchLinger(t *testing.T) {
+
+	// the generator is pausing after every 2 items
+	i := 0
+	generator := func() (int, error) {
+		if i >= len(ints) {
+			return 0, io.EOF
+		}
+		if i%2 == 0 && i > 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		i++
+		return ints[i-1], nil
+	}
+
+	nop := func(_ context.Context, item int) (int, error) { return item, nil }
+
+	input := NewGeneratorStream(context.Background(), nop)(generator)
+	batches := collectBatches(t, Batch(context.Background(), input, BatchLingerOpt(5*time.Millisecond)))
+
+	if want, got := [][]int{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9}}, batches; !slices.EqualFunc(want, got, slices.Equal) {
+		t.Fatalf("Expected batches %v, got %v", want, got)
+	}
+}
+
+func TestBatchStream(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		sum := func(_ context.Context, batch []int) ([]int, error) {
+			s := 0
+			for _, item := range batch {
+				s += item
+			}
+			return []int{s}, nil
+		}
+
+		stream := NewStream(context.Background(), sum, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true))
+
+		iter := Unbatch(stream(Batch(context.Background(), FromSlice(ints), BatchSizeOpt(3))))
+		defer iter.Close()
+
+		for _, want := range []int{6, 15, 24} {
+			got, err := iter.Next()
+			if err != nil {
+				t.Fatalf("test %d: %v", testnr, err)
+			}
+			if want != got {
+				t.Fatalf("test %d: Expected sum %d, got %d", testnr, want, got)
+			}
+		}
+
+		if _, err := iter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+	}
+}
+
+// closableInts is an iterator returning the given items and blocking afterwards until it is closed.
+type closableInts struct {
+	items  chan int
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newClosableInts(items ...int) *closableInts {
+	it := &closableInts{items: make(chan int, len(items)), closed: make(chan struct{})}
+	for _, item := range items {
+		it.items <- item
+	}
+	return it
+}
+
+func (c *closableInts) Next() (int, error) {
+	select {
+	case item := <-c.items:
+		return item, nil
+	default:
+	}
+
+	select {
+	case item := <-c.items:
+		return item, nil
+	case <-c.closed:
+		return 0, io.EOF
+	}
+}
+
+func (c *closableInts) Close() {
+	c.once.Do(func() { close(c.closed) })
+}
+
+func TestBatchInputClosed(t *testing.T) {
+
+	in := newClosableInts(1, 2)
+
+	iter := Batch[int](context.Background(), in, BatchSizeOpt(10))
+	defer iter.Close()
+
+	// the partial batch is emitted when the input is closed by another goroutine
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		in.Close()
+	}()
+
+	batch, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := []int{1, 2}, batch; !slices.Equal(want, got) {
+		t.Fatalf("Expected batch %v, got %v", want, got)
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
+
+func TestBatchClose(t *testing.T) {
+
+	in := &closeRecorder{TypedIterator: FromSeq(slices.Values(ints))}
+
+	iter := Batch(context.Background(), in, BatchSizeOpt(2))
+
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	iter.Close()
+
+	if !in.closed {
+		t.Fatal("Expected input to be closed")
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
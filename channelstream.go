@@ -2,19 +2,27 @@ package iter
 
 import "context"
 
-// ChannelStream is a function that creates a new Iterator for processing and streaming of data
+// TypedChannelStream is a function that creates a new TypedIterator for processing and streaming of data
 // from the given inputChan, while listening for errors on errChan.
-type ChannelStream func(inputChan chan interface{}, errChan chan error) Iterator
+type TypedChannelStream[In, Out any] func(inputChan chan In, errChan chan error) TypedIterator[Out]
 
-// NewChannelStream is setting up a ChannelStream func with the given Mapper func and StreamOpts.
-func NewChannelStream(ctx context.Context, mapper Mapper, opts ...StreamOpt) ChannelStream {
+// ChannelStream is the untyped version of TypedChannelStream, working on items of type interface{}.
+type ChannelStream = TypedChannelStream[interface{}, interface{}]
 
-	myCtx, cancel := context.WithCancel(ctx)
+// NewChannelStream is setting up a TypedChannelStream func with the given TypedMapper func and StreamOpts.
+// Each call of the returned func is starting an independent stream, so it can be used as a template.
+// The stream is stopping to read from the input channels when it is closed or finished.
+func NewChannelStream[In, Out any](ctx context.Context, mapper TypedMapper[In, Out], opts ...StreamOpt) TypedChannelStream[In, Out] {
 
-	return func(inputChan chan interface{}, errChan chan error) Iterator {
+	// the channels are not owned by the stream, so closing the input iterator is always safe
+	stream := NewStream(ctx, mapper, append(opts[:len(opts):len(opts)], CloseInputOpt(true))...)
 
-		inIter := New(inputChan, errChan, cancel)
+	return func(inputChan chan In, errChan chan error) TypedIterator[Out] {
 
-		return NewStream(myCtx, mapper, opts...)(inIter)
+		inCtx, cancel := context.WithCancel(ctx)
+
+		inIter := &iterator[In]{itemChan: inputChan, errChan: errChan, cancel: cancel, stop: inCtx.Done()}
+
+		return stream(inIter)
 	}
 }
@@ -0,0 +1,181 @@
+package iter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Control is a handle for changing the configuration of running streams, see ControlOpt. It is safe for
+// concurrent use. When a stream func set up with ControlOpt is called multiple times, e.g. as a template,
+// the Control is applied to all the streams started from it.
+type Control struct {
+	mu      sync.Mutex
+	streams map[controlled]struct{}
+
+	// workers and cont are the values set for the streams, taken from the first stream if not set yet.
+	workers int
+	cont    *bool
+	// resume is closed when resuming, it is nil if not paused.
+	resume chan struct{}
+}
+
+// controlled is a running stream attached to a Control.
+type controlled interface {
+	// setWorkers is changing the number of workers, returning the number which is applied.
+	setWorkers(n int) int
+	// workers is returning the current number of workers, which is changing in adaptive mode.
+	workers() int
+	setContinueOnError(cont bool)
+}
+
+// NewControl is returning a new Control, which can be passed to streams with ControlOpt.
+func NewControl() *Control {
+	return &Control{streams: map[controlled]struct{}{}}
+}
+
+// SetWorkers is changing the number of workers calling the Mapper concurrently. Additional worker goroutines
+// are started as needed, while surplus workers are waiting before their next Mapper call. With KeyedOpt, the
+// number of lanes is fixed, so the number is capped to it. With AdaptiveWorkersOpt, the number is kept
+// between the Min and Max of the policy and adapted further from there. In ordered mode, the reorder window
+// is not changed. SetWorkers is returning the number applied to the streams.
+func (c *Control) SetWorkers(n int) int {
+	if n < 1 {
+		panic(fmt.Sprintf("nr of stream Workers: %d - need a least 1 worker", n))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.workers = n
+	for s := range c.streams {
+		c.workers = s.setWorkers(n)
+	}
+	return c.workers
+}
+
+// Workers is returning the current number of workers of the running streams, following the changes of
+// AdaptiveWorkersOpt - the highest one if several streams are running. Without running streams, it is
+// returning the number last set, or the initial number of the first stream.
+func (c *Control) Workers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.streams) == 0 {
+		return c.workers
+	}
+
+	workers := 0
+	for s := range c.streams {
+		workers = max(workers, s.workers())
+	}
+	return workers
+}
+
+// SetContinueOnError is changing whether the streams are continuing after an error, see ContOnErrOpt.
+func (c *Control) SetContinueOnError(cont bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cont = &cont
+	for s := range c.streams {
+		s.setContinueOnError(cont)
+	}
+}
+
+// ContinueOnError is returning whether the streams are continuing after an error.
+func (c *Control) ContinueOnError() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cont != nil && *c.cont
+}
+
+// Pause is stopping the workers from pulling further items from the source of the streams, until Resume
+// is called. Items which were already pulled are still processed and delivered.
+func (c *Control) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resume == nil {
+		c.resume = make(chan struct{})
+	}
+}
+
+// Resume is letting the workers continue pulling items after Pause.
+func (c *Control) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resume != nil {
+		close(c.resume)
+		c.resume = nil
+	}
+}
+
+// Paused is returning true if the streams are paused.
+func (c *Control) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.resume != nil
+}
+
+// wait is blocking while the streams are paused. It returns false if the context was done before.
+func (c *Control) wait(ctx context.Context) bool {
+	if c == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	resume := c.resume
+	c.mu.Unlock()
+
+	if resume == nil {
+		return true
+	}
+
+	select {
+	case <-resume:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// attach is applying the values set so far to a starting stream, or takes the initial values of the stream
+// if not set yet - in adaptive mode, the initial number of workers is the Min of the policy.
+func (c *Control) attach(s controlled, cont bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.streams[s] = struct{}{}
+
+	if c.workers == 0 {
+		c.workers = s.workers()
+	} else {
+		s.setWorkers(c.workers)
+	}
+
+	if c.cont == nil {
+		c.cont = &cont
+	} else {
+		s.setContinueOnError(*c.cont)
+	}
+}
+
+// detach is removing a finished stream.
+func (c *Control) detach(s controlled) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.streams, s)
+}
@@ -0,0 +1,266 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestControlWorkers(t *testing.T) {
+
+	for testnr, ordered := range []bool{false, true} {
+
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+
+		mapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+
+			return squareMapper(ctx, input)
+		}
+
+		ctrl := NewControl()
+		iter := NewStream(context.Background(), mapper, OrderedOpt(ordered), ControlOpt(ctrl))(&testIter{list: list})
+
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		if want, got := 1, ctrl.Workers(); want != got {
+			t.Fatalf("test %d: Expected %d workers, got %d", testnr, want, got)
+		}
+
+		ctrl.SetWorkers(4)
+
+		items, err := Collect(context.Background(), iter)
+		if err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		if want, got := len(list)-1, len(items); want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+
+		if maxInFlight < 2 || maxInFlight > 4 {
+			t.Fatalf("test %d: Expected 2 to 4 concurrent Mapper calls, got %d", testnr, maxInFlight)
+		}
+	}
+}
+
+func TestControlWorkersKeyed(t *testing.T) {
+
+	key := func(item int) int { return item % 2 }
+	nop := func(_ context.Context, item int) (int, error) { return item, nil }
+
+	ctrl := NewControl()
+	iter := NewStream(context.Background(), nop, WorkersOpt(2), KeyedOpt(key), ControlOpt(ctrl))(FromSlice(ints))
+
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the number of workers is capped to the number of lanes
+	if want, got := 2, ctrl.SetWorkers(10); want != got {
+		t.Fatalf("Expected %d workers to be set, got %d", want, got)
+	}
+
+	if want, got := 2, ctrl.Workers(); want != got {
+		t.Fatalf("Expected %d workers, got %d", want, got)
+	}
+
+	if _, err := Collect(context.Background(), iter); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestControlWorkersExhausted(t *testing.T) {
+
+	var pulled, eofs atomic.Int32
+	generator := func() (int, error) {
+		n := int(pulled.Add(1))
+		if n > 3 {
+			eofs.Add(1)
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+
+	release := make(chan struct{})
+	mapper := func(_ context.Context, item int) (int, error) {
+		if item == 3 {
+			<-release
+		}
+		return item, nil
+	}
+
+	ctrl := NewControl()
+	iter := NewGeneratorStream(context.Background(), mapper, WorkersOpt(2), BufSizeOpt(3), ControlOpt(ctrl))(generator)
+	defer iter.Close()
+
+	// one worker is mapping the last item while the other one has seen the end of the generator
+	for eofs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the generator must not be called again by new workers
+	ctrl.SetWorkers(5)
+	close(release)
+
+	items, err := Collect(context.Background(), iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 3, len(items); want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+
+	if want, got := int32(1), eofs.Load(); want != got {
+		t.Fatalf("Expected %d generator call after the end, got %d", want, got)
+	}
+}
+
+func TestControlPause(t *testing.T) {
+
+	var pulled atomic.Int32
+	generator := func() (interface{}, error) {
+		n := int(pulled.Add(1))
+		if n > len(list) {
+			return nil, io.EOF
+		}
+		return list[n-1], nil
+	}
+
+	ctrl := NewControl()
+	ctrl.Pause()
+
+	iter := NewGeneratorStream(context.Background(), squareMapper, WorkersOpt(3), ControlOpt(ctrl))(generator)
+
+	time.Sleep(20 * time.Millisecond)
+	if got := pulled.Load(); got != 0 {
+		t.Fatalf("Expected no pulled items while paused, got %d", got)
+	}
+
+	if !ctrl.Paused() {
+		t.Fatal("Expected the stream to be paused")
+	}
+
+	ctrl.Resume()
+
+	items, err := Collect(context.Background(), iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := len(list), len(items); want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+
+	// closing a paused stream is unblocking the workers
+	ctrl.Pause()
+	iter = NewGeneratorStream(context.Background(), squareMapper, WorkersOpt(3), ControlOpt(ctrl))(generator)
+
+	closed := make(chan struct{})
+	go func() {
+		iter.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to return while paused")
+	}
+}
+
+func TestControlPauseFailed(t *testing.T) {
+
+	key := func(item int) int { return item }
+	modes := []StreamOpt{OrderedOpt(false), OrderedOpt(true), KeyedOpt(key)}
+
+	for testnr, mode := range modes {
+
+		ctrl := NewControl()
+
+		// the failing worker is pausing the stream, the fatal error needs to unblock the other workers
+		mapper := func(_ context.Context, item int) (int, error) {
+			ctrl.Pause()
+			return 0, errFive
+		}
+
+		iter := NewStream(context.Background(), mapper, WorkersOpt(3), ControlOpt(ctrl), mode)(FromSlice(ints))
+
+		done := make(chan error)
+		go func() {
+			var err error
+			for err == nil {
+				_, err = iter.Next()
+			}
+			if errors.Is(err, errFive) {
+				_, err = iter.Next()
+			}
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if err != io.EOF {
+				t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("test %d: Expected the failed stream to finish while paused", testnr)
+		}
+		iter.Close()
+	}
+}
+
+func TestControlContOnError(t *testing.T) {
+
+	ctrl := NewControl()
+	iter := NewStream(context.Background(), failingMapper, ControlOpt(ctrl))(&testIter{list: list})
+	defer iter.Close()
+
+	ctrl.SetContinueOnError(true)
+
+	if !ctrl.ContinueOnError() {
+		t.Fatal("Expected continue-on-error to be set")
+	}
+
+	errs := 0
+	items := 0
+	for {
+		_, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errFive) {
+			errs++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items++
+	}
+
+	if want, got := 1, errs; want != got {
+		t.Fatalf("Expected %d errors, got %d", want, got)
+	}
+
+	if want, got := len(list)-1, items; want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+}
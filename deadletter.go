@@ -0,0 +1,94 @@
+package iter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// DeadLetterSink is receiving the failed items of a stream configured with DeadLetterOpt.
+// Put is called concurrently by the worker goroutines, so implementations need to be threadsafe.
+type DeadLetterSink interface {
+	Put(ctx context.Context, err *StreamError) error
+}
+
+// DeadLetterFunc is an adapter to use a func as DeadLetterSink.
+type DeadLetterFunc func(ctx context.Context, err *StreamError) error
+
+// Put is calling the DeadLetterFunc.
+func (f DeadLetterFunc) Put(ctx context.Context, err *StreamError) error {
+	return f(ctx, err)
+}
+
+// DeadLetterChan is returning a DeadLetterSink sending the failed items to the given channel.
+// Use New to construct an Iterator for consuming the channel.
+func DeadLetterChan(ch chan<- *StreamError) DeadLetterSink {
+	return DeadLetterFunc(func(ctx context.Context, err *StreamError) error {
+		select {
+		case ch <- err:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// MemoryDeadLetters is a DeadLetterSink collecting the failed items in memory.
+type MemoryDeadLetters struct {
+	mu   sync.Mutex
+	errs []*StreamError
+}
+
+// Put is appending the failed item to the collected ones.
+func (m *MemoryDeadLetters) Put(_ context.Context, err *StreamError) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.errs = append(m.errs, err)
+	return nil
+}
+
+// Errors is returning a copy of the failed items collected so far.
+func (m *MemoryDeadLetters) Errors() []*StreamError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*StreamError(nil), m.errs...)
+}
+
+// JSONLinesDeadLetters is a DeadLetterSink writing the failed items as JSON lines, e.g. to a file.
+type JSONLinesDeadLetters struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLinesDeadLetters is returning a new *JSONLinesDeadLetters writing to w.
+func NewJSONLinesDeadLetters(w io.Writer) *JSONLinesDeadLetters {
+	return &JSONLinesDeadLetters{enc: json.NewEncoder(w)}
+}
+
+// deadLetter is the JSON representation of a failed item.
+type deadLetter struct {
+	Stage    string      `json:"stage,omitempty"`
+	Index    uint64      `json:"index"`
+	Worker   int         `json:"worker"`
+	Attempts int         `json:"attempts"`
+	Error    string      `json:"error"`
+	Item     interface{} `json:"item"`
+}
+
+// Put is writing the failed item as a single JSON line. The item needs to be serializable by encoding/json.
+func (j *JSONLinesDeadLetters) Put(_ context.Context, err *StreamError) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.enc.Encode(deadLetter{
+		Stage:    err.Stage,
+		Index:    err.Index,
+		Worker:   err.Worker,
+		Attempts: err.Attempts,
+		Error:    err.Err.Error(),
+		Item:     err.Item,
+	})
+}
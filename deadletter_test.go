@@ -0,0 +1,157 @@
+package iter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestDeadLetters(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		sink := &MemoryDeadLetters{}
+
+		stream := NewStream(context.Background(), failingMapper,
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), ContOnErrOpt(true), DeadLetterOpt(sink))
+
+		iter := stream(&testIter{list: list})
+		defer iter.Close()
+
+		n := 0
+		for {
+			a, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("test %d: Expected only successful items, got %v", testnr, err)
+			}
+			if a.(data).input == 5 {
+				t.Fatalf("test %d: Expected failed item not to be returned", testnr)
+			}
+			n++
+		}
+
+		if want, got := len(list)-1, n; want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+
+		errs := sink.Errors()
+		if want, got := 1, len(errs); want != got {
+			t.Fatalf("test %d: Expected %d dead letter, got %d", testnr, want, got)
+		}
+
+		if want, got := 5, errs[0].Item.(data).input; want != got {
+			t.Fatalf("test %d: Expected dead letter item %d, got %d", testnr, want, got)
+		}
+	}
+}
+
+func TestDeadLettersWithoutContOnError(t *testing.T) {
+
+	sink := &MemoryDeadLetters{}
+
+	iter := NewStream(context.Background(), failingMapper, DeadLetterOpt(sink))(&testIter{list: list})
+	defer iter.Close()
+
+	var err error
+	for err == nil {
+		_, err = iter.Next()
+	}
+
+	if !errors.Is(err, errFive) {
+		t.Fatalf("Expected errFive to stop the stream, got %v", err)
+	}
+
+	if want, got := 1, len(sink.Errors()); want != got {
+		t.Fatalf("Expected %d dead letter, got %d", want, got)
+	}
+}
+
+func TestDeadLetterChan(t *testing.T) {
+
+	ch := make(chan *StreamError, 1)
+
+	iter := NewStream(context.Background(), failingMapper, ContOnErrOpt(true), DeadLetterOpt(DeadLetterChan(ch)))(&testIter{list: list})
+	defer iter.Close()
+
+	for {
+		if _, err := iter.Next(); err == io.EOF {
+			break
+		}
+	}
+
+	errs := New(ch, nil, func() {})
+	close(ch)
+
+	streamErr, err := errs.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 5, streamErr.Item.(data).input; want != got {
+		t.Fatalf("Expected dead letter item %d, got %d", want, got)
+	}
+}
+
+func TestDeadLetterSinkError(t *testing.T) {
+
+	errSink := errors.New("sink failed")
+
+	sink := DeadLetterFunc(func(_ context.Context, _ *StreamError) error {
+		return errSink
+	})
+
+	iter := NewStream(context.Background(), failingMapper, ContOnErrOpt(true), DeadLetterOpt(sink))(&testIter{list: list})
+	defer iter.Close()
+
+	var err error
+	for err == nil {
+		_, err = iter.Next()
+	}
+
+	if !errors.Is(err, errSink) {
+		t.Fatalf("Expected the sink error to stop the stream, got %v", err)
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
+
+func TestJSONLinesDeadLetters(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+
+	mapper := func(_ context.Context, input int) (int, error) {
+		if input == 5 {
+			return 0, errFive
+		}
+		return input, nil
+	}
+
+	generator := (&testIter{list: list}).Next
+	ints := func() (int, error) {
+		item, err := generator()
+		if err != nil {
+			return 0, err
+		}
+		return item.(data).input, nil
+	}
+
+	iter := NewGeneratorStream(context.Background(), mapper, NameOpt("ints"), ContOnErrOpt(true), DeadLetterOpt(NewJSONLinesDeadLetters(buf)))(ints)
+
+	for {
+		if _, err := iter.Next(); err == io.EOF {
+			break
+		}
+	}
+	iter.Close()
+
+	if want, got := `{"stage":"ints","index":4,"worker":0,"attempts":1,"error":"I don't like 5","item":5}`+"\n", buf.String(); want != got {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,88 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrSkip can be returned by a Mapper func to drop an item from the stream without reporting an error.
+var ErrSkip = errors.New("skip item")
+
+// ErrItemTimeout is reported when a Mapper call failed after exceeding the deadline set with ItemTimeoutOpt.
+var ErrItemTimeout = errors.New("item timeout")
+
+// StreamError is wrapping an error returned by the Generator or Mapper func of a stream, together
+// with the context of the failed item. Use errors.Is or errors.As to inspect the wrapped error.
+type StreamError struct {
+	// Stage is the name of the stream as set with NameOpt.
+	Stage string
+	// Item is the input item of the failed Mapper call - nil if the Generator func failed.
+	Item interface{}
+	// Index is the sequence index of the item, counting the items in the order they were pulled
+	// from the source. With multiple workers in unordered mode, items pulled concurrently may
+	// get their indexes in a slightly different order.
+	Index uint64
+	// Worker is the id of the worker goroutine which processed the item.
+	Worker int
+	// Attempts is the number of Mapper calls for the item - 0 if the Generator func failed.
+	Attempts int
+	// Err is the error returned by the Generator or Mapper func.
+	Err error
+}
+
+// Error is implementing the error interface.
+func (e *StreamError) Error() string {
+	var attempts string
+	if e.Attempts > 1 {
+		attempts = fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+
+	if e.Stage != "" {
+		return fmt.Sprintf("stream %q, item %d, worker %d%s: %v", e.Stage, e.Index, e.Worker, attempts, e.Err)
+	}
+	return fmt.Sprintf("stream item %d, worker %d%s: %v", e.Index, e.Worker, attempts, e.Err)
+}
+
+// Unwrap is returning the wrapped error.
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+// isStreamError is returning true if the given error is wrapping a *StreamError, e.g. when
+// the error was returned by an upstream stream.
+func isStreamError(err error) bool {
+	var streamErr *StreamError
+	return errors.As(err, &streamErr)
+}
+
+// PanicError is reported when a Generator or Mapper func panicked. It is wrapped into a StreamError.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// Error is implementing the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap is returning the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// protect is calling f and converts a panic into a *PanicError, unless it should re-panic.
+func protect[T any](repanic bool, f func() (T, error)) (res T, err error) {
+	if !repanic {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+	return f()
+}
@@ -0,0 +1,190 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestStreamError(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		ctx := context.Background()
+		opts := []StreamOpt{BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true)}
+
+		// errors of the inner stream are passed through by the outer stream unchanged
+		inner := NewStream(ctx, failingMapper, append(opts, NameOpt("inner"))...)
+		outer := NewStream(ctx, nopMapper, append(opts, NameOpt("outer"))...)
+
+		iter := outer(inner(&testIter{list: list}))
+		defer iter.Close()
+
+		var err error
+		for i := 0; i < len(list) && err == nil; i++ {
+			_, err = iter.Next()
+		}
+
+		var streamErr *StreamError
+		if !errors.As(err, &streamErr) {
+			t.Fatalf("test %d: Expected a *StreamError, got %v", testnr, err)
+		}
+
+		if !errors.Is(err, errFive) {
+			t.Fatalf("test %d: Expected errFive to be wrapped, got %v", testnr, err)
+		}
+
+		if want, got := "inner", streamErr.Stage; want != got {
+			t.Fatalf("test %d: Expected stage %q, got %q", testnr, want, got)
+		}
+
+		if want, got := 5, streamErr.Item.(data).input; want != got {
+			t.Fatalf("test %d: Expected failed item %d, got %d", testnr, want, got)
+		}
+
+		if want, got := uint64(4), streamErr.Index; want != got {
+			t.Fatalf("test %d: Expected index %d, got %d", testnr, want, got)
+		}
+
+		if streamErr.Worker < 0 || streamErr.Worker >= parms.workers {
+			t.Fatalf("test %d: Expected worker id in [0, %d), got %d", testnr, parms.workers, streamErr.Worker)
+		}
+	}
+}
+
+func TestStreamErrorGenerator(t *testing.T) {
+
+	generator := func() (interface{}, error) {
+		return nil, errFive
+	}
+
+	iter := NewGeneratorStream(context.Background(), nopMapper, NameOpt("gen"))(generator)
+	defer iter.Close()
+
+	_, err := iter.Next()
+
+	var streamErr *StreamError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Expected a *StreamError, got %v", err)
+	}
+
+	if streamErr.Item != nil {
+		t.Fatalf("Expected no item for a generator error, got %v", streamErr.Item)
+	}
+
+	if want, got := `stream "gen", item 0, worker 0: I don't like 5`, err.Error(); want != got {
+		t.Fatalf("Expected error message %q, got %q", want, got)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		panickingMapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+			if input.(data).input == 5 {
+				panic(errFive)
+			}
+			return squareMapper(ctx, input)
+		}
+
+		stream := NewStream(context.Background(), panickingMapper,
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), ContOnErrOpt(true))
+
+		iter := stream(&testIter{list: list})
+		defer iter.Close()
+
+		n := 0
+		for {
+			_, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				var panicErr *PanicError
+				if !errors.As(err, &panicErr) {
+					t.Fatalf("test %d: Expected a *PanicError, got %v", testnr, err)
+				}
+				if !errors.Is(err, errFive) {
+					t.Fatalf("test %d: Expected the panic value to be wrapped, got %v", testnr, err)
+				}
+				if len(panicErr.Stack) == 0 {
+					t.Fatalf("test %d: Expected a stack trace", testnr)
+				}
+				continue
+			}
+			n++
+		}
+
+		if want, got := len(list)-1, n; want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+	}
+}
+
+func TestPanicErrorGenerator(t *testing.T) {
+
+	generator := func() (int, error) {
+		panic("out of items")
+	}
+
+	iter := NewGeneratorStream(context.Background(), func(_ context.Context, in int) (int, error) { return in, nil },
+		OrderedOpt(true), WorkersOpt(3))(generator)
+	defer iter.Close()
+
+	_, err := iter.Next()
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a *PanicError, got %v", err)
+	}
+
+	if want, got := "out of items", panicErr.Value; want != got {
+		t.Fatalf("Expected panic value %q, got %v", want, got)
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
+
+func TestPanicErrorKey(t *testing.T) {
+
+	key := func(item int) int {
+		if item == 3 {
+			panic("no key")
+		}
+		return item
+	}
+
+	// the type mismatch of the key func is panicking in the dispatcher as well
+	mismatch := func(item string) string {
+		return item
+	}
+
+	for testnr, opt := range []StreamOpt{KeyedOpt(key), KeyedOpt(mismatch)} {
+
+		iter := NewStream(context.Background(), func(_ context.Context, in int) (int, error) { return in, nil },
+			WorkersOpt(3), opt)(FromSlice([]int{1, 2, 3, 4}))
+
+		var err error
+		for err == nil {
+			_, err = iter.Next()
+		}
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("test %d: Expected a *PanicError, got %v", testnr, err)
+		}
+
+		// items of other lanes may still be delivered before the stream is stopped
+		for err = nil; err == nil; {
+			_, err = iter.Next()
+		}
+		if err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+		iter.Close()
+	}
+}
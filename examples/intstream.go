@@ -6,7 +6,8 @@ import (
 	"github.com/hphilipps/iter"
 )
 
-// IntIter is an example for how to construct streaming iterators for specific types using the generic Iterator.
+// IntIter is an example for how to construct streaming iterators for specific types using the untyped Iterator.
+// With the typed API the same can be achieved by just using iter.TypedIterator[int] (see typed_example_test.go).
 type IntIter struct {
 	iter.Iterator
 }
@@ -0,0 +1,52 @@
+package examples
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hphilipps/iter"
+)
+
+// An example of how to use the typed API: the types of the mapper funcs are inferred and checked
+// at compile time, so neither a wrapper like IntIter nor type assertions are needed.
+func TestTypedStreamer(t *testing.T) {
+
+	inputChan := make(chan string)
+	errChan := make(chan error)
+
+	go func() {
+		for _, s := range testData {
+			inputChan <- s
+		}
+		close(inputChan)
+	}()
+
+	upper := func(_ context.Context, input string) (string, error) {
+		return strings.ToUpper(input), nil
+	}
+
+	charCount := func(_ context.Context, input string) (int, error) {
+		return len(input), nil
+	}
+
+	ctx := context.Background()
+	outIter := iter.NewStream(ctx, charCount)(iter.NewChannelStream(ctx, upper)(inputChan, errChan))
+	defer outIter.Close()
+
+	for i := 0; i < len(testData); i++ {
+		a, err := outIter.Next()
+		if err != nil {
+			t.Fatalf("item %s: %v", testData[i], err)
+		}
+
+		if want, got := len(testData[i]), a; want != got {
+			t.Fatalf("item %s: Expected len %d, got %d", testData[i], want, got)
+		}
+	}
+
+	if _, err := outIter.Next(); err != io.EOF {
+		t.Fatal("Expected io.EOF")
+	}
+}
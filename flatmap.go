@@ -0,0 +1,214 @@
+package iter
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// TypedPredicate is the signature of a func deciding whether an item of type T is matching a condition.
+type TypedPredicate[T any] func(ctx context.Context, item T) (bool, error)
+
+// Predicate is the untyped version of TypedPredicate, working on items of type interface{}.
+type Predicate = TypedPredicate[interface{}]
+
+// NewFilterStream is setting up a TypedStream func which is only passing the items matching the given
+// TypedPredicate func. The predicate is applied by the worker goroutines like a Mapper func.
+func NewFilterStream[T any](ctx context.Context, predicate TypedPredicate[T], opts ...StreamOpt) TypedStream[T, T] {
+
+	mapper := func(ctx context.Context, item T) (T, error) {
+		keep, err := predicate(ctx, item)
+		if err == nil && !keep {
+			err = ErrSkip
+		}
+		return item, err
+	}
+
+	return NewStream(ctx, mapper, opts...)
+}
+
+// NewFlatMapStream is setting up a TypedStream func with a mapper func returning a slice of output items
+// for every input item. The output items are streamed one by one in the order of the slice.
+func NewFlatMapStream[In, Out any](ctx context.Context, mapper TypedMapper[In, []Out], opts ...StreamOpt) TypedStream[In, Out] {
+
+	iterMapper := func(ctx context.Context, item In) (TypedIterator[Out], error) {
+		items, err := mapper(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		return FromSlice(items), nil
+	}
+
+	return NewFlatMapIterStream(ctx, iterMapper, opts...)
+}
+
+// NewFlatMapIterStream is setting up a TypedStream func with a mapper func returning a TypedIterator
+// of output items for every input item. The output iterators are consumed one after another by the
+// consumer of the stream, so the work done in their Next methods is not parallelized by the workers.
+// Every output iterator is closed, including the ones which were not consumed when the stream was
+// closed or failed.
+func NewFlatMapIterStream[In, Out any](ctx context.Context, mapper TypedMapper[In, TypedIterator[Out]], opts ...StreamOpt) TypedStream[In, Out] {
+
+	cfg := newStreamConf(opts...)
+
+	return func(inIter TypedIterator[In]) TypedIterator[Out] {
+		owned := &ownedIterators[Out]{iters: map[*ownedIterator[Out]]struct{}{}}
+		return &flatIterator[Out]{outer: startIterStream(ctx, cfg, track(owned, mapper), inIter), owned: owned}
+	}
+}
+
+// ownedIterator is an output iterator of a mapper, tracked until it is taken over by the flatIterator.
+type ownedIterator[T any] struct {
+	TypedIterator[T]
+}
+
+// ownedIterators is tracking the output iterators of a mapper which were not taken over yet, so they can be
+// closed if they are discarded by the stream.
+type ownedIterators[T any] struct {
+	mu     sync.Mutex
+	iters  map[*ownedIterator[T]]struct{}
+	closed bool
+}
+
+// track is returning a mapper func tracking the iterators returned by the given mapper func.
+func track[In, T any](o *ownedIterators[T], mapper TypedMapper[In, TypedIterator[T]]) TypedMapper[In, TypedIterator[T]] {
+	return func(ctx context.Context, item In) (TypedIterator[T], error) {
+		inner, err := mapper(ctx, item)
+		if err != nil || inner == nil {
+			return inner, err
+		}
+
+		owned := &ownedIterator[T]{inner}
+
+		o.mu.Lock()
+		closed := o.closed
+		if !closed {
+			o.iters[owned] = struct{}{}
+		}
+		o.mu.Unlock()
+
+		// the stream is already finished, so the iterator would be discarded
+		if closed {
+			inner.Close()
+		}
+		return owned, nil
+	}
+}
+
+// take is untracking an iterator taken over by the flatIterator.
+func (o *ownedIterators[T]) take(it TypedIterator[T]) {
+	if o == nil {
+		return
+	}
+	if owned, ok := it.(*ownedIterator[T]); ok {
+		o.mu.Lock()
+		delete(o.iters, owned)
+		o.mu.Unlock()
+	}
+}
+
+// closeAll is closing the iterators which were not taken over, and the ones tracked later on.
+func (o *ownedIterators[T]) closeAll() {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	o.closed = true
+	iters := o.iters
+	o.iters = map[*ownedIterator[T]]struct{}{}
+	o.mu.Unlock()
+
+	for it := range iters {
+		it.Close()
+	}
+}
+
+// flatIterator is implementing the TypedIterator interface by iterating through the items of the
+// iterators returned by the outer iterator.
+type flatIterator[T any] struct {
+	// mu is serializing the calls of Next, it is not held by Close.
+	mu    sync.Mutex
+	outer TypedIterator[TypedIterator[T]]
+	// owned are the output iterators of the mapper which were not received from outer yet, nil for Unbatch.
+	owned *ownedIterators[T]
+
+	// innerMu is guarding the current inner iterator, which is closed by either Next or Close.
+	innerMu sync.Mutex
+	inner   TypedIterator[T]
+
+	closed    atomic.Bool
+	closeOnce sync.Once
+}
+
+// Next is returning the next item of the current inner iterator, moving on to the next inner iterator
+// when the current one is exhausted. Errors of the inner and outer iterators are passed through.
+func (i *flatIterator[T]) Next() (T, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var zero T
+
+	for {
+		if i.closed.Load() {
+			return zero, io.EOF
+		}
+
+		i.innerMu.Lock()
+		inner := i.inner
+		i.innerMu.Unlock()
+
+		if inner == nil {
+			inner, err := i.outer.Next()
+			if err == io.EOF {
+				// the iterators discarded by the finished stream are not received anymore
+				i.owned.closeAll()
+			}
+			if err != nil {
+				return zero, err
+			}
+			i.owned.take(inner)
+
+			i.innerMu.Lock()
+			i.inner = inner
+			i.innerMu.Unlock()
+
+			// Close may have missed the new inner iterator
+			if i.closed.Load() {
+				i.closeInner()
+			}
+			continue
+		}
+
+		item, err := inner.Next()
+		if err == io.EOF {
+			i.closeInner()
+			continue
+		}
+		return item, err
+	}
+}
+
+// closeInner is closing the current inner iterator, if any.
+func (i *flatIterator[T]) closeInner() {
+	i.innerMu.Lock()
+	inner := i.inner
+	i.inner = nil
+	i.innerMu.Unlock()
+
+	if inner != nil {
+		inner.Close()
+	}
+}
+
+// Close is closing the current inner iterator and the outer iterator, which is unblocking a pending
+// call of Next.
+func (i *flatIterator[T]) Close() {
+	i.closeOnce.Do(func() {
+		i.closed.Store(true)
+		i.outer.Close()
+		i.owned.closeAll()
+		i.closeInner()
+	})
+}
@@ -0,0 +1,257 @@
+package iter
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// skippingMapper is squaring the input, skipping the odd items.
+func skippingMapper(ctx context.Context, input interface{}) (interface{}, error) {
+	if input.(data).input%2 != 0 {
+		return nil, ErrSkip
+	}
+	return squareMapper(ctx, input)
+}
+
+func TestErrSkip(t *testing.T) {
+
+	for testnr, parms := range testCases {
+		for _, ordered := range []bool{false, true} {
+
+			stream := NewStream(context.Background(), skippingMapper,
+				BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(ordered))
+
+			iter := stream(&testIter{list: list})
+			defer iter.Close()
+
+			n := 0
+			for {
+				a, err := iter.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("test %d: Expected skipped items not to be reported, got %v", testnr, err)
+				}
+				if a.(data).input%2 != 0 {
+					t.Fatalf("test %d: Expected odd item %d to be skipped", testnr, a.(data).input)
+				}
+				if ordered {
+					if want, got := 2*(n+1), a.(data).input; want != got {
+						t.Fatalf("test %d: Expected item %d, got %d", testnr, want, got)
+					}
+				}
+				n++
+			}
+
+			if want, got := 4, n; want != got {
+				t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+			}
+		}
+	}
+}
+
+func TestFilterStream(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		even := func(_ context.Context, item int) (bool, error) {
+			return item%2 == 0, nil
+		}
+
+		stream := NewFilterStream(context.Background(), even, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true))
+
+		iter := stream(FromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}))
+		defer iter.Close()
+
+		for _, want := range []int{2, 4, 6, 8} {
+			got, err := iter.Next()
+			if err != nil {
+				t.Fatalf("test %d: %v", testnr, err)
+			}
+			if want != got {
+				t.Fatalf("test %d: Expected item %d, got %d", testnr, want, got)
+			}
+		}
+
+		if _, err := iter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+	}
+}
+
+func TestFlatMapStream(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		// 0 is expanded to no items at all
+		repeat := func(_ context.Context, item int) ([]int, error) {
+			items := []int{}
+			for i := 0; i < item; i++ {
+				items = append(items, item)
+			}
+			return items, nil
+		}
+
+		stream := NewFlatMapStream(context.Background(), repeat, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true))
+
+		iter := stream(FromSlice([]int{1, 0, 2, 3}))
+		defer iter.Close()
+
+		for _, want := range []int{1, 2, 2, 3, 3, 3} {
+			got, err := iter.Next()
+			if err != nil {
+				t.Fatalf("test %d: %v", testnr, err)
+			}
+			if want != got {
+				t.Fatalf("test %d: Expected item %d, got %d", testnr, want, got)
+			}
+		}
+
+		if _, err := iter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+	}
+}
+
+func TestFlatMapIterStream(t *testing.T) {
+
+	split := func(_ context.Context, item []int) (TypedIterator[int], error) {
+		return FromSlice(item), nil
+	}
+
+	iter := NewFlatMapIterStream(context.Background(), split)(FromSlice([][]int{{1, 2}, {}, {3}}))
+	defer iter.Close()
+
+	for _, want := range []int{1, 2, 3} {
+		got, err := iter.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != got {
+			t.Fatalf("Expected item %d, got %d", want, got)
+		}
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
+
+// blockingInts is returning an iterator blocking in Next until it is closed.
+func blockingInts() TypedIterator[int] {
+	nop := func(_ context.Context, item int) (int, error) { return item, nil }
+	return NewChannelStream(context.Background(), nop)(make(chan int), make(chan error))
+}
+
+// closeWhileBlocked is checking that Close is unblocking a pending call of Next of the given iterator.
+func closeWhileBlocked[T any](t *testing.T, it TypedIterator[T]) {
+	t.Helper()
+
+	next := make(chan error)
+	go func() {
+		_, err := it.Next()
+		next <- err
+	}()
+
+	// let Next block
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		it.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to return while Next is blocked")
+	}
+
+	select {
+	case err := <-next:
+		if err != io.EOF {
+			t.Fatalf("Expected io.EOF, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to unblock Next")
+	}
+}
+
+func TestFlatMapClose(t *testing.T) {
+
+	split := func(_ context.Context, item int) ([]int, error) {
+		return []int{item, item}, nil
+	}
+
+	closeWhileBlocked(t, NewFlatMapStream(context.Background(), split)(blockingInts()))
+
+	// Unbatch is built on the same iterator
+	nop := func(_ context.Context, batch []int) ([]int, error) { return batch, nil }
+	closeWhileBlocked(t, Unbatch(NewChannelStream(context.Background(), nop)(make(chan []int), make(chan error))))
+}
+
+// closeCounter is an iterator counting the calls of Close.
+type closeCounter struct {
+	TypedIterator[int]
+	closed *atomic.Int32
+}
+
+func (c *closeCounter) Close() {
+	c.closed.Add(1)
+	c.TypedIterator.Close()
+}
+
+func TestFlatMapIterClose(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		var produced, closed atomic.Int32
+
+		mapper := func(_ context.Context, item int) (TypedIterator[int], error) {
+			produced.Add(1)
+			return &closeCounter{TypedIterator: FromSlice([]int{item, item}), closed: &closed}, nil
+		}
+
+		stream := NewFlatMapIterStream(context.Background(), mapper, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers))
+
+		// the output iterators which were not consumed are closed as well
+		iter := stream(FromSlice(ints))
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+		iter.Close()
+
+		if want, got := produced.Load(), closed.Load(); want != got {
+			t.Fatalf("test %d: Expected %d closed iterators, got %d", testnr, want, got)
+		}
+
+		// and the ones discarded by a failed stream
+		failing := func(ctx context.Context, item int) (TypedIterator[int], error) {
+			if item == 5 {
+				return nil, errFive
+			}
+			return mapper(ctx, item)
+		}
+
+		produced.Store(0)
+		closed.Store(0)
+
+		iter = NewFlatMapIterStream(context.Background(), failing, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers))(FromSlice(ints))
+		for {
+			_, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+		}
+
+		if want, got := produced.Load(), closed.Load(); want != got {
+			t.Fatalf("test %d: Expected %d closed iterators of the failed stream, got %d", testnr, want, got)
+		}
+		iter.Close()
+	}
+}
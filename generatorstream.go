@@ -2,83 +2,579 @@ package iter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
 
-// Generator is the signature of a generator func.
-type Generator func() (interface{}, error)
+// TypedGenerator is the signature of a generator func producing items of type T.
+type TypedGenerator[T any] func() (T, error)
 
-// GeneratorStream is a function that creates a new Iterator for processing and streaming of data
-// from the given Generator func.
-type GeneratorStream func(Generator) Iterator
+// Generator is the untyped version of TypedGenerator, producing items of type interface{}.
+type Generator = TypedGenerator[interface{}]
 
-// NewGeneratorStream is setting up a GeneratorStream func with the given Mapper func and StreamOpts.
-func NewGeneratorStream(ctx context.Context, mapper Mapper, opts ...StreamOpt) GeneratorStream {
+// TypedGeneratorStream is a function that creates a new TypedIterator for processing and streaming of data
+// from the given TypedGenerator func.
+type TypedGeneratorStream[In, Out any] func(TypedGenerator[In]) TypedIterator[Out]
 
-	cfg := newStreamConf()
-	for _, opt := range opts {
-		opt(cfg)
+// GeneratorStream is the untyped version of TypedGeneratorStream, working on items of type interface{}.
+type GeneratorStream = TypedGeneratorStream[interface{}, interface{}]
+
+// NewGeneratorStream is setting up a TypedGeneratorStream func with the given TypedMapper func and StreamOpts.
+// Each call of the returned func is starting an independent stream, so it can be used as a template.
+func NewGeneratorStream[In, Out any](ctx context.Context, mapper TypedMapper[In, Out], opts ...StreamOpt) TypedGeneratorStream[In, Out] {
+
+	cfg := newStreamConf(opts...)
+
+	return func(next TypedGenerator[In]) TypedIterator[Out] {
+		return startStream(ctx, cfg, mapper, next, nil)
 	}
+}
+
+// startStream is setting up the channels of a new stream instance and starting the worker goroutines
+// applying the mapper func to the items of the generator. The inputCloser func is closing the input
+// of the stream, if it has one.
+func startStream[In, Out any](ctx context.Context, cfg *streamConf, mapper TypedMapper[In, Out], next TypedGenerator[In], inputCloser func()) StreamIterator[Out] {
 
-	itemChan := make(chan interface{}, cfg.BufSize)
+	itemChan := make(chan Out, cfg.BufSize)
 	errChan := make(chan error)
+	done := make(chan struct{})
 
-	myCtx, cancel := context.WithCancel(ctx)
+	myCtx, cancel := context.WithCancelCause(ctx)
 
-	iter := New(itemChan, errChan, cancel)
+	iter := newStreamIterator(itemChan, errChan, func() { cancel(ErrClosed) }, done)
 
-	return func(next Generator) Iterator {
+	p := &pipeline[In, Out]{
+		cfg:      cfg,
+		ctx:      myCtx,
+		next:     next,
+		mapper:   mapper,
+		itemChan: itemChan,
+		errChan:  errChan,
+		limiter:  newRateLimiter(cfg),
+		limit:    newConcurrencyLimit(cfg),
+		target:   cfg.Workers,
+	}
+	p.cont.Store(cfg.ContinueOnError)
 
-		go func() {
-			defer close(itemChan)
+	go func() {
+		defer close(done)
+		defer close(itemChan)
 
-			// errgroup for worker goroutines - all workers will be canceled after the first error
-			eg, egCtx := errgroup.WithContext(myCtx)
+		// errgroup for worker goroutines - all workers will be canceled after the first error
+		eg, egCtx := errgroup.WithContext(myCtx)
 
-			for i := 0; i < cfg.Workers; i++ {
+		// close the input as soon as the stream was canceled or finished, which is also unblocking
+		// workers waiting for upstream items
+		if inputCloser != nil {
+			finished := make(chan struct{})
+			inputClosed := make(chan struct{})
 
-				eg.Go(func() error {
+			go func() {
+				defer close(inputClosed)
+				select {
+				case <-egCtx.Done():
+				case <-finished:
+				}
+				inputCloser()
+			}()
+
+			defer func() { <-inputClosed }()
+			defer close(finished)
+		}
 
-					for {
-						var res interface{}
-
-						item, err := next()
-
-						if err == nil {
-							res, err = mapper(myCtx, item)
-						}
-
-						if err != nil {
-							if err == io.EOF {
-								return nil
-							}
-
-							select {
-							case errChan <- err:
-								if cfg.ContinueOnError {
-									continue
-								}
-								return err
-							case <-egCtx.Done():
-								return nil
-							}
-						}
-
-						select {
-						case itemChan <- res:
-						case <-egCtx.Done():
-							return nil
-						}
-					}
+		cfg.Control.attach(p, cfg.ContinueOnError)
+
+		if cfg.Ordered {
+			p.runOrdered(eg, egCtx)
+		} else if cfg.Lane != nil {
+			p.runKeyed(eg, egCtx)
+		} else {
+			p.spawn = func(id int) {
+				eg.Go(func() error {
+					defer p.exited()
+					return p.worker(egCtx, id)
 				})
 			}
+			p.startWorkers()
+		}
+
+		// wait for all Workers to finish or cancel the remaining ones after the first error
+		err := eg.Wait()
+		cfg.Control.detach(p)
+
+		if err != nil {
+			iter.finish(StateFailed, err)
+		} else if myCtx.Err() != nil {
+			iter.finish(StateCanceled, context.Cause(myCtx))
+		} else {
+			iter.finish(StateCompleted, nil)
+		}
+	}()
+
+	return iter
+}
+
+// pipeline is holding the state shared by the worker goroutines of a stream.
+type pipeline[In, Out any] struct {
+	cfg      *streamConf
+	ctx      context.Context
+	next     TypedGenerator[In]
+	mapper   TypedMapper[In, Out]
+	itemChan chan Out
+	errChan  chan error
+	limiter  *rateLimiter
+	limit    *concurrencyLimit
+
+	// seq is the sequence number of the next item pulled from the generator.
+	seq atomic.Uint64
+	// mu is serializing the generator calls in ordered mode, so sequence numbers match the pull order.
+	mu sync.Mutex
+	// cont is whether to continue on errors, which can be changed by a Control.
+	cont atomic.Bool
+	// exhausted is set when the generator returned io.EOF, it is written while holding workersMu.
+	exhausted atomic.Bool
+
+	// spawn is starting a worker goroutine with the given id, if the mode is supporting more workers at runtime.
+	spawn func(id int)
+	// workersMu is guarding the target number of worker goroutines and the counts of started and active ones.
+	workersMu sync.Mutex
+	target    int
+	started   int
+	active    int
+}
+
+// startWorkers is starting worker goroutines until the target number is started. Once the generator is
+// exhausted or all workers have exited, no more are started, as the stream is finishing then.
+func (p *pipeline[In, Out]) startWorkers() {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+
+	if p.spawn == nil || p.exhausted.Load() || (p.started > 0 && p.active == 0) {
+		return
+	}
+
+	for ; p.started < p.target; p.started++ {
+		p.active++
+		p.spawn(p.started)
+	}
+}
+
+// exited is called by a worker goroutine started by startWorkers when exiting.
+func (p *pipeline[In, Out]) exited() {
+	p.workersMu.Lock()
+	defer p.workersMu.Unlock()
+
+	p.active--
+}
+
+// setWorkers is changing the concurrency limit of the workers and starts more workers if needed.
+// In adaptive mode, the max number of workers is started anyway.
+func (p *pipeline[In, Out]) setWorkers(n int) int {
+	n = p.limit.set(n)
+
+	if p.cfg.Adaptive == nil {
+		p.workersMu.Lock()
+		p.target = n
+		p.workersMu.Unlock()
+	}
+
+	p.startWorkers()
+	return n
+}
+
+// workers is returning the current concurrency limit of the workers.
+func (p *pipeline[In, Out]) workers() int {
+	return p.limit.current()
+}
+
+// setContinueOnError is changing whether to continue on errors.
+func (p *pipeline[In, Out]) setContinueOnError(cont bool) {
+	p.cont.Store(cont)
+}
+
+// result is the output of a worker for a single input item, tagged with the sequence number of the item.
+type result[T any] struct {
+	seq  uint64
+	item T
+	err  error
+}
+
+// newResult is returning a result without sequence number.
+func newResult[T any](item T, err error) result[T] {
+	return result[T]{item: item, err: err}
+}
+
+// readAll is starting a goroutine pulling the items of the given iterator and sending them to the returned
+// channel, converted by the given func right after pulling. The channel is closed when the iterator is
+// exhausted or the context is done. It is used by the stages which need to wait for their input and other
+// events at the same time.
+func readAll[T, R any](ctx context.Context, it TypedIterator[T], convert func(item T, err error) R) <-chan R {
+	ch := make(chan R)
+
+	go func() {
+		defer close(ch)
+		for {
+			item, err := it.Next()
+			if err == io.EOF {
+				return
+			}
+
+			select {
+			case ch <- convert(item, err):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// pull is pulling the next item from the generator, converting a panic into an error.
+// While paused by a Control, it is waiting to be resumed and returns io.EOF if the given worker context
+// is done, i.e. the stream is canceled or failed. Once the generator returned io.EOF, it is not called again.
+func (p *pipeline[In, Out]) pull(ctx context.Context) (In, error) {
+	var zero In
+
+	if !p.cfg.Control.wait(ctx) || p.exhausted.Load() {
+		return zero, io.EOF
+	}
+
+	item, err := protect(p.cfg.Repanic, p.next)
+	if err == io.EOF {
+		p.workersMu.Lock()
+		p.exhausted.Store(true)
+		p.workersMu.Unlock()
+	}
+	return item, err
+}
+
+// apply is applying the mapper func to an item pulled from the generator, unless pulling the item failed.
+// Errors are wrapped into a *StreamError, except for an io.EOF or ErrSkip returned by the mapper and
+// errors of an upstream stream, which are already wrapped.
+func (p *pipeline[In, Out]) apply(ctx context.Context, worker int, index uint64, item In, err error) (Out, error) {
+	var res Out
+
+	if err != nil {
+		if isStreamError(err) {
+			return res, err
+		}
+		return res, &StreamError{Stage: p.cfg.Name, Index: index, Worker: worker, Err: err}
+	}
+
+	res, attempts, err := p.mapItem(ctx, item)
+	if errors.Is(err, ErrSkip) {
+		return res, ErrSkip
+	}
+	if err != nil && err != io.EOF {
+		return res, &StreamError{Stage: p.cfg.Name, Item: item, Index: index, Worker: worker, Attempts: attempts, Err: err}
+	}
+	return res, err
+}
+
+// mapItem is applying the mapper func to an item pulled from the generator, retrying failed calls
+// according to the retry policy. It returns the number of attempts together with the result.
+// The given context is the one of the worker, which is canceled when another worker failed.
+func (p *pipeline[In, Out]) mapItem(ctx context.Context, item In) (Out, int, error) {
+
+	for attempt := 1; ; attempt++ {
+		res, err := p.mapAttempt(ctx, item)
+
+		if err == nil || !p.cfg.Retry.retryable(attempt, err) {
+			return res, attempt, err
+		}
+
+		if !sleep(ctx, p.cfg.Retry.backoff(attempt)) {
+			return res, attempt, err
+		}
+	}
+}
+
+// mapAttempt is calling the mapper func once, after waiting for the rate limit and the concurrency limit,
+// if configured.
+func (p *pipeline[In, Out]) mapAttempt(ctx context.Context, item In) (Out, error) {
+
+	var zero Out
+
+	if err := p.limiter.wait(ctx, item); err != nil {
+		return zero, err
+	}
+
+	if !p.limit.acquire(ctx) {
+		return zero, context.Cause(ctx)
+	}
+
+	start := time.Now()
+	res, err := p.call(ctx, item)
+	p.limit.release(time.Since(start), err)
+
+	return res, err
+}
 
-			// wait for all Workers to finish or cancel the remaining ones after the first error
-			eg.Wait()
+// call is calling the mapper func, with a deadline for the item if an item timeout is configured.
+func (p *pipeline[In, Out]) call(ctx context.Context, item In) (Out, error) {
+
+	if p.cfg.ItemTimeout <= 0 {
+		return protect(p.cfg.Repanic, func() (Out, error) {
+			return p.mapper(ctx, item)
+		})
+	}
+
+	itemCtx, cancel := context.WithTimeout(ctx, p.cfg.ItemTimeout)
+	defer cancel()
+
+	res, err := protect(p.cfg.Repanic, func() (Out, error) {
+		return p.mapper(itemCtx, item)
+	})
+
+	// only report a timeout if the deadline of the item was exceeded, not if the stream was canceled
+	if err != nil && itemCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		err = fmt.Errorf("%w after %v: %w", ErrItemTimeout, p.cfg.ItemTimeout, err)
+	}
+
+	return res, err
+}
+
+// send is delivering a result to the iterator. It returns false if the calling goroutine should stop,
+// together with the error that should cancel the errgroup (if any).
+// Failed items are routed to the dead-letter sink, if configured. A failing sink is stopping the stream.
+func (p *pipeline[In, Out]) send(ctx context.Context, res result[Out]) (bool, error) {
+
+	// skipped items are dropped silently
+	if res.err == ErrSkip {
+		return true, nil
+	}
+
+	// results of a worker which was canceled after another one failed are not delivered anymore
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	cont := p.cont.Load()
+
+	var streamErr *StreamError
+	if res.err != nil && p.cfg.DeadLetters != nil && errors.As(res.err, &streamErr) {
+		if err := p.cfg.DeadLetters.Put(ctx, streamErr); err != nil {
+			res.err = fmt.Errorf("dead letter sink: %w", err)
+			cont = false
+		} else if cont {
+			return true, nil
+		}
+	}
+
+	if res.err != nil {
+		select {
+		case p.errChan <- res.err:
+			if cont {
+				return true, nil
+			}
+			return false, res.err
+		case <-ctx.Done():
+			return false, nil
+		}
+	}
+
+	select {
+	case p.itemChan <- res.item:
+		return true, nil
+	case <-ctx.Done():
+		return false, nil
+	}
+}
+
+// worker is the loop of a worker goroutine in unordered mode, sending the results directly to the iterator.
+func (p *pipeline[In, Out]) worker(ctx context.Context, id int) error {
+
+	for {
+		item, err := p.pull(ctx)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		seq := p.seq.Add(1) - 1
+
+		res, err := p.apply(ctx, id, seq, item, err)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if ok, err := p.send(ctx, result[Out]{seq: seq, item: res, err: err}); !ok {
+			return err
+		}
+	}
+}
+
+// runOrdered is starting the worker goroutines in ordered mode. Each item is tagged with a sequence number
+// when pulled from the generator and a resequencer goroutine is delivering the results in that order.
+// The amount of items in flight (pulled from the generator but not yet delivered) is bounded by the
+// reorder window, so a slow item is stalling the workers instead of letting the pending results grow.
+func (p *pipeline[In, Out]) runOrdered(eg *errgroup.Group, ctx context.Context) {
+
+	window := p.cfg.ReorderWindow
+	if window == 0 {
+		window = 2 * p.cfg.Workers
+	}
+
+	slots := make(chan struct{}, window)
+	results := make(chan result[Out], window)
+
+	wg := sync.WaitGroup{}
+
+	p.spawn = func(id int) {
+		wg.Add(1)
+		eg.Go(func() error {
+			defer wg.Done()
+			defer p.exited()
+			return p.orderedWorker(ctx, id, slots, results)
+		})
+	}
+	p.startWorkers()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// resequencer
+	eg.Go(func() error {
+		pending := map[uint64]result[Out]{}
+		var seq uint64
+
+		for res := range results {
+			pending[res.seq] = res
+
+			for {
+				res, ok := pending[seq]
+				if !ok {
+					break
+				}
+				delete(pending, seq)
+				seq++
+
+				if ok, err := p.send(ctx, res); !ok {
+					return err
+				}
+				<-slots
+			}
+		}
+		return nil
+	})
+}
+
+// orderedWorker is the loop of a worker goroutine in ordered mode, sending the results to the resequencer.
+func (p *pipeline[In, Out]) orderedWorker(ctx context.Context, id int, slots chan struct{}, results chan<- result[Out]) error {
+
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		p.mu.Lock()
+		item, err := p.pull(ctx)
+		var seq uint64
+		if err != io.EOF {
+			seq = p.seq.Add(1) - 1
+		}
+		p.mu.Unlock()
+
+		if err == io.EOF {
+			<-slots
+			return nil
+		}
+
+		res, err := p.apply(ctx, id, seq, item, err)
+
+		// like in unordered mode, the worker is stopping after the mapper returned io.EOF - the item
+		// is skipped, so the resequencer is not waiting for it
+		if err == io.EOF {
+			results <- result[Out]{seq: seq, err: ErrSkip}
+			return nil
+		}
+
+		// never blocking, as the results channel has the capacity of the reorder window
+		results <- result[Out]{seq: seq, item: res, err: err}
+
+		// the resequencer is stopping the stream after delivering the error
+		if err != nil && err != ErrSkip && !p.cont.Load() {
+			return nil
+		}
+	}
+}
+
+// runKeyed is starting the worker goroutines in keyed mode. A dispatcher goroutine is pulling the items
+// from the generator and sends them to the lane of the worker assigned to the key of the item, so items
+// with the same key are processed sequentially in the order they were pulled.
+// Errors of the generator and of the key func are processed by the worker of the first lane.
+func (p *pipeline[In, Out]) runKeyed(eg *errgroup.Group, ctx context.Context) {
+
+	lanes := make([]chan result[In], p.cfg.Workers)
+
+	for i := range lanes {
+		lanes[i] = make(chan result[In], 1)
+
+		eg.Go(func() error {
+			return p.laneWorker(ctx, i, lanes[i])
+		})
+	}
+
+	// dispatcher
+	eg.Go(func() error {
+		defer func() {
+			for _, lane := range lanes {
+				close(lane)
+			}
 		}()
 
-		return iter
+		for {
+			item, err := p.pull(ctx)
+			if err == io.EOF {
+				return nil
+			}
+
+			// a panicking key func is failing the item like a panicking generator
+			lane := 0
+			if err == nil {
+				lane, err = protect(p.cfg.Repanic, func() (int, error) {
+					return p.cfg.Lane(item, len(lanes)), nil
+				})
+			}
+
+			select {
+			case lanes[lane] <- result[In]{seq: p.seq.Add(1) - 1, item: item, err: err}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// laneWorker is the loop of a worker goroutine in keyed mode, processing the items of its lane.
+func (p *pipeline[In, Out]) laneWorker(ctx context.Context, id int, lane <-chan result[In]) error {
+
+	for in := range lane {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		res, err := p.apply(ctx, id, in.seq, in.item, in.err)
+
+		if err == io.EOF {
+			continue
+		}
+
+		if ok, err := p.send(ctx, result[Out]{seq: in.seq, item: res, err: err}); !ok {
+			return err
+		}
 	}
+	return nil
 }
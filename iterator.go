@@ -3,25 +3,52 @@ package iter
 import (
 	"context"
 	"io"
+	"sync"
 )
 
-// Iterator is the interface for an object that can be used to iterate through a set of items.
-type Iterator interface {
-	Next() (interface{}, error)
+// TypedIterator is the interface for an object that can be used to iterate through a set of items of type T.
+type TypedIterator[T any] interface {
+	Next() (T, error)
 	Close()
 }
 
-// iterator is implementing the Iterator interface.
+// Iterator is the untyped version of TypedIterator, working on items of type interface{}.
+type Iterator = TypedIterator[interface{}]
+
+// CloseWaiter is implemented by iterators which can wait for the goroutines of their stream to exit.
+// The iterators returned by the stream constructors are implementing it as part of StreamIterator.
+type CloseWaiter interface {
+	CloseWait(ctx context.Context) error
+}
+
+// iterator is implementing the TypedIterator interface.
 // You need to call Close() to cancel the go routines of the related stream.
-type iterator struct {
-	itemChan chan interface{}
+type iterator[T any] struct {
+	itemChan chan T
 	errChan  chan error
 	cancel   context.CancelFunc
+
+	// done is closed after all goroutines of the stream have exited - nil if there is nothing to wait for.
+	done chan struct{}
+
+	// stop is letting Next return io.EOF without waiting for the channels when closed - nil if not needed.
+	stop <-chan struct{}
+
+	// mu is guarding the terminal state of the stream, which is set before the itemChan is closed.
+	mu       sync.Mutex
+	terminal State
+	err      error
 }
 
 // New is returning a new *iterator instance.
-func New(itemChan chan interface{}, errChan chan error, cancel context.CancelFunc) Iterator {
-	return &iterator{itemChan: itemChan, errChan: errChan, cancel: cancel}
+func New[T any](itemChan chan T, errChan chan error, cancel context.CancelFunc) TypedIterator[T] {
+	return &iterator[T]{itemChan: itemChan, errChan: errChan, cancel: cancel}
+}
+
+// newStreamIterator is returning a new *iterator instance for a stream which is closing the done channel
+// after its goroutines have exited, its terminal state was set and the itemChan was closed.
+func newStreamIterator[T any](itemChan chan T, errChan chan error, cancel context.CancelFunc, done chan struct{}) *iterator[T] {
+	return &iterator[T]{itemChan: itemChan, errChan: errChan, cancel: cancel, done: done}
 }
 
 // Next is returning the next item from the stream or an io.EOF error when the stream is closed.
@@ -29,22 +56,101 @@ func New(itemChan chan interface{}, errChan chan error, cancel context.CancelFun
 // or multiple worker goroutines (which not all may have been canceled yet).
 // When the stream was configured with continue-on-error, the goroutines will not be canceled after
 // any other error and try to stream further items.
-// When using multiple stream workers the result order is unpredictable.
-func (i *iterator) Next() (interface{}, error) {
+// When using multiple stream workers the result order is unpredictable, unless the stream was configured
+// to be ordered.
+func (i *iterator[T]) Next() (T, error) {
+	var zero T
+
+	// prefer items which were already sent, so an error is not overtaking buffered results
+	select {
+	case item, ok := <-i.itemChan:
+		if ok {
+			return item, nil
+		}
+	default:
+	}
 
 	select {
 	case item, ok := <-i.itemChan:
 		if !ok { // channel was closed by sender
-			return nil, io.EOF
+			return zero, io.EOF
 		}
 		return item, nil
 
 	case err := <-i.errChan:
-		return nil, err
+		return zero, err
+
+	case <-i.stop:
+		return zero, io.EOF
 	}
 }
 
-// Close is sending a cancel signal to all goroutines of the stream.
-func (i *iterator) Close() {
+// Close is sending a cancel signal to all goroutines of the stream and waits for them to exit.
+// Results which were already buffered are discarded, so subsequent calls to Next are returning io.EOF.
+// Close is safe to be called multiple times.
+// Close is blocking as long as a worker is stuck in a Generator or Mapper func not respecting
+// the cancellation of its context - use CloseWait to bound the time to wait.
+func (i *iterator[T]) Close() {
+	i.CloseWait(context.Background())
+}
+
+// CloseWait is like Close, but stops waiting for the goroutines of the stream when the given context is done,
+// returning the error of the context.
+func (i *iterator[T]) CloseWait(ctx context.Context) error {
 	i.cancel()
+
+	if i.done == nil {
+		return nil
+	}
+
+	select {
+	case <-i.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// discard the results which were buffered before the stream was canceled
+	for range i.itemChan {
+	}
+
+	return nil
+}
+
+// Err is returning the error which stopped the stream, see StreamIterator.
+func (i *iterator[T]) Err() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.err
+}
+
+// Done is returning a channel which is closed after all goroutines of the stream have exited.
+// It is nil for iterators created with New, which are not knowing about the goroutines feeding them.
+func (i *iterator[T]) Done() <-chan struct{} {
+	return i.done
+}
+
+// State is returning the current state of the stream.
+func (i *iterator[T]) State() State {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.terminal == StateRunning {
+		return StateRunning
+	}
+
+	if len(i.itemChan) > 0 {
+		return StateDraining
+	}
+
+	return i.terminal
+}
+
+// finish is setting the terminal state of the stream after its workers have exited.
+func (i *iterator[T]) finish(state State, err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.terminal = state
+	i.err = err
 }
@@ -0,0 +1,259 @@
+package iter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+)
+
+// MergePolicy is creating the func which is picking the input of a merge whose item is emitted next,
+// given the indexes of the n inputs with an item ready. It is called once per merged iterator.
+type MergePolicy func(n int) func(ready []int) int
+
+// FirstAvailablePolicy is a MergePolicy emitting the items in the order they arrive from the inputs,
+// picking randomly if items of multiple inputs are ready.
+func FirstAvailablePolicy(n int) func(ready []int) int {
+	return func(ready []int) int {
+		return ready[rand.IntN(len(ready))]
+	}
+}
+
+// RoundRobinPolicy is a MergePolicy taking turns between the inputs with an item ready, so a fast
+// input can not starve the others.
+func RoundRobinPolicy(n int) func(ready []int) int {
+	last := -1
+	return func(ready []int) int {
+		// pick the first ready input after the last picked one
+		for _, idx := range ready {
+			if idx > last {
+				last = idx
+				return idx
+			}
+		}
+		last = ready[0]
+		return last
+	}
+}
+
+// WeightedPolicy is returning a MergePolicy picking the inputs with an item ready randomly, with a
+// probability proportional to their weights. Inputs without weight are getting a weight of 1.
+func WeightedPolicy(weights ...int) MergePolicy {
+	for _, w := range weights {
+		if w < 1 {
+			panic(fmt.Sprintf("merge weight: %d - need a weight of at least 1", w))
+		}
+	}
+
+	return func(n int) func(ready []int) int {
+		weight := func(idx int) int {
+			if idx < len(weights) {
+				return weights[idx]
+			}
+			return 1
+		}
+
+		return func(ready []int) int {
+			total := 0
+			for _, idx := range ready {
+				total += weight(idx)
+			}
+
+			r := rand.IntN(total)
+			for _, idx := range ready {
+				if r < weight(idx) {
+					return idx
+				}
+				r -= weight(idx)
+			}
+			return ready[len(ready)-1]
+		}
+	}
+}
+
+type mergeConf struct {
+	Policy          MergePolicy
+	ContinueOnError bool
+}
+
+// newMergeConf is creating a default merge config and applies the given MergeOpts.
+func newMergeConf(opts ...MergeOpt) *mergeConf {
+	conf := &mergeConf{
+		Policy:          FirstAvailablePolicy,
+		ContinueOnError: false,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// MergeOpt is a functional option type for NewMerge.
+type MergeOpt func(conf *mergeConf)
+
+// MergePolicyOpt is a functional option setting the policy for picking the next input (default: FirstAvailablePolicy).
+func MergePolicyOpt(policy MergePolicy) MergeOpt {
+	return func(conf *mergeConf) {
+		conf.Policy = policy
+	}
+}
+
+// MergeContOnErrOpt is a functional option that lets the merge continue after an error of an input if set
+// to true (default: false). Otherwise the merge is stopping after returning the first error.
+func MergeContOnErrOpt(cont bool) MergeOpt {
+	return func(conf *mergeConf) {
+		conf.ContinueOnError = cont
+	}
+}
+
+// Merge is returning a TypedIterator merging the items of the given iterators with the default MergeOpts.
+func Merge[T any](ctx context.Context, iters ...TypedIterator[T]) TypedIterator[T] {
+	return NewMerge[T](ctx)(iters...)
+}
+
+// NewMerge is setting up a func merging the items of the given iterators into a single TypedIterator.
+// The merged iterator is returning io.EOF after all inputs are exhausted. All inputs are closed when
+// the merged iterator is closed or finished.
+func NewMerge[T any](ctx context.Context, opts ...MergeOpt) func(iters ...TypedIterator[T]) TypedIterator[T] {
+
+	cfg := newMergeConf(opts...)
+
+	return func(iters ...TypedIterator[T]) TypedIterator[T] {
+
+		itemChan := make(chan T)
+		errChan := make(chan error)
+		done := make(chan struct{})
+
+		myCtx, cancel := context.WithCancelCause(ctx)
+
+		iter := newStreamIterator(itemChan, errChan, func() { cancel(ErrClosed) }, done)
+
+		// every input is read by its own goroutine, which is sending an io.EOF result when exhausted
+		inputs := make([]chan result[T], len(iters))
+		notify := make(chan struct{}, 1)
+		wg := sync.WaitGroup{}
+
+		for idx, it := range iters {
+			inputs[idx] = make(chan result[T], 1)
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				for {
+					item, err := it.Next()
+
+					select {
+					case inputs[idx] <- result[T]{item: item, err: err}:
+					case <-myCtx.Done():
+						return
+					}
+
+					select {
+					case notify <- struct{}{}:
+					default:
+					}
+
+					if err == io.EOF {
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			defer close(done)
+			defer close(itemChan)
+
+			// closing the inputs is unblocking the readers
+			defer func() {
+				cancel(nil)
+				for _, it := range iters {
+					it.Close()
+				}
+				wg.Wait()
+			}()
+
+			m := merger[T]{cfg: cfg, inputs: inputs, notify: notify, itemChan: itemChan, errChan: errChan}
+
+			if err := m.run(myCtx); err != nil {
+				iter.finish(StateFailed, err)
+			} else if myCtx.Err() != nil {
+				iter.finish(StateCanceled, context.Cause(myCtx))
+			} else {
+				iter.finish(StateCompleted, nil)
+			}
+		}()
+
+		return iter
+	}
+}
+
+// merger is emitting the items of the inputs of a merge according to the merge policy.
+type merger[T any] struct {
+	cfg      *mergeConf
+	inputs   []chan result[T]
+	notify   chan struct{}
+	itemChan chan T
+	errChan  chan error
+}
+
+// run is emitting the items of the inputs until all inputs are exhausted or the context is done.
+// It returns the error which stopped the merge, if any.
+func (m *merger[T]) run(ctx context.Context) error {
+
+	pick := m.cfg.Policy(len(m.inputs))
+	finished := make([]bool, len(m.inputs))
+	remaining := len(m.inputs)
+	ready := make([]int, 0, len(m.inputs))
+
+	for remaining > 0 {
+
+		ready = ready[:0]
+		for idx, input := range m.inputs {
+			if !finished[idx] && len(input) > 0 {
+				ready = append(ready, idx)
+			}
+		}
+
+		if len(ready) == 0 {
+			select {
+			case <-m.notify:
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		idx := pick(ready)
+
+		// never blocking, as the merger is the only receiver
+		res := <-m.inputs[idx]
+
+		if res.err == io.EOF {
+			finished[idx] = true
+			remaining--
+			continue
+		}
+
+		if res.err != nil {
+			select {
+			case m.errChan <- res.err:
+				if !m.cfg.ContinueOnError {
+					return res.err
+				}
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		select {
+		case m.itemChan <- res.item:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+
+	policies := []MergePolicy{FirstAvailablePolicy, RoundRobinPolicy, WeightedPolicy(1, 2, 3)}
+
+	for testnr, policy := range policies {
+
+		merge := NewMerge[int](context.Background(), MergePolicyOpt(policy))
+		iter := merge(FromSlice([]int{1, 2, 3}), FromSlice([]int{4, 5}), FromSlice([]int{}), FromSlice([]int{6, 7, 8, 9}))
+		defer iter.Close()
+
+		items := []int{}
+		for {
+			item, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("test %d: %v", testnr, err)
+			}
+			items = append(items, item)
+		}
+
+		slices.Sort(items)
+		if want, got := ints, items; !slices.Equal(want, got) {
+			t.Fatalf("test %d: Expected items %v, got %v", testnr, want, got)
+		}
+	}
+}
+
+func TestMergeError(t *testing.T) {
+
+	failing := func() TypedIterator[interface{}] {
+		return NewStream(context.Background(), failingMapper, OrderedOpt(true))(&testIter{list: list})
+	}
+
+	// the merge is stopping after the first error by default
+	iter := Merge(context.Background(), failing(), failing())
+	defer iter.Close()
+
+	var err error
+	for err == nil {
+		_, err = iter.Next()
+	}
+
+	if !errors.Is(err, errFive) {
+		t.Fatalf("Expected errFive, got %v", err)
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+
+	// with continue-on-error, the errors of both inputs are returned
+	iter = NewMerge[interface{}](context.Background(), MergeContOnErrOpt(true))(failing(), failing())
+	defer iter.Close()
+
+	errs := 0
+	items := 0
+	for {
+		_, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errFive) {
+			errs++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items++
+	}
+
+	if want, got := 2, errs; want != got {
+		t.Fatalf("Expected %d errors, got %d", want, got)
+	}
+
+	if want, got := 8, items; want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+}
+
+func TestMergeClose(t *testing.T) {
+
+	inputs := []*closeRecorder{
+		{TypedIterator: FromSeq(slices.Values(ints))},
+		{TypedIterator: FromSeq(slices.Values(ints))},
+	}
+
+	iter := Merge[int](context.Background(), inputs[0], inputs[1])
+
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	iter.Close()
+
+	for i, in := range inputs {
+		if !in.closed {
+			t.Fatalf("Expected input %d to be closed", i)
+		}
+	}
+}
+
+func TestRoundRobinPolicy(t *testing.T) {
+
+	pick := RoundRobinPolicy(3)
+
+	for i, tc := range []struct {
+		ready []int
+		want  int
+	}{
+		{ready: []int{0, 1, 2}, want: 0},
+		{ready: []int{0, 1, 2}, want: 1},
+		{ready: []int{0, 2}, want: 2},
+		{ready: []int{0, 1}, want: 0},
+		{ready: []int{0, 2}, want: 2},
+	} {
+		if got := pick(tc.ready); tc.want != got {
+			t.Fatalf("pick %d: Expected input %d, got %d", i, tc.want, got)
+		}
+	}
+}
+
+func TestWeightedPolicy(t *testing.T) {
+
+	pick := WeightedPolicy(9, 1)(2)
+
+	picks := [2]int{}
+	for i := 0; i < 10000; i++ {
+		picks[pick([]int{0, 1})]++
+	}
+
+	if picks[0] < 8500 || picks[0] > 9500 {
+		t.Fatalf("Expected input 0 to be picked about 9000 times, got %d", picks[0])
+	}
+}
@@ -0,0 +1,117 @@
+package iter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// minSweep is the amount of per-key buckets at which the full buckets are removed, as they are not
+// different from new buckets.
+const minSweep = 1024
+
+// rateLimiter is a token bucket limiting the rate of the Mapper calls of all workers of a stream,
+// with a bucket per key if a key func is set.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	key     func(item interface{}) interface{}
+	repanic bool
+	buckets map[interface{}]*bucket
+	sweepAt int
+}
+
+// bucket is the state of a token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter is returning a rateLimiter for the rate limit of the given config, or nil if not configured.
+func newRateLimiter(cfg *streamConf) *rateLimiter {
+	if cfg.Rate <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		rate:    cfg.Rate,
+		burst:   float64(cfg.Burst),
+		key:     cfg.RateKey,
+		repanic: cfg.Repanic,
+		buckets: map[interface{}]*bucket{},
+		sweepAt: minSweep,
+	}
+}
+
+// wait is blocking until the rate limit is allowing a call for the given item. It returns the cause of the
+// context if it was done before, or a PanicError if the key func panicked.
+func (l *rateLimiter) wait(ctx context.Context, item interface{}) error {
+	if l == nil {
+		return nil
+	}
+
+	var key interface{}
+	if l.key != nil {
+		var err error
+		key, err = protect(l.repanic, func() (interface{}, error) {
+			return l.key(item), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	d := l.reserve(key)
+	if d <= 0 {
+		return nil
+	}
+
+	if !sleep(ctx, d) {
+		// the reserved token was not used
+		l.mu.Lock()
+		if b, ok := l.buckets[key]; ok {
+			b.tokens++
+		}
+		l.mu.Unlock()
+		return context.Cause(ctx)
+	}
+
+	return nil
+}
+
+// reserve is taking a token from the bucket of the given key, returning the time to wait until it is available.
+func (l *rateLimiter) reserve(key interface{}) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.sweepAt {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.rate * float64(time.Second))
+}
+
+// sweep is removing the buckets which are full at the given time - the mutex needs to be held.
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.sweepAt = max(minSweep, 2*len(l.buckets))
+}
@@ -0,0 +1,143 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+
+	start := time.Now()
+
+	// the limit is shared by all workers, so the 9 items need at least 8 intervals after the first one
+	stream := NewStream(context.Background(), squareMapper, WorkersOpt(3), RateLimitOpt(100, 1))
+
+	items, err := Collect(context.Background(), stream(&testIter{list: list}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := len(list), len(items); want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Fatalf("Expected the stream to take at least 70ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimitKeyed(t *testing.T) {
+
+	var mu sync.Mutex
+	calls := map[int][]time.Time{}
+
+	key := func(item interface{}) int { return item.(data).input % 4 }
+
+	mapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+		mu.Lock()
+		calls[key(input)] = append(calls[key(input)], time.Now())
+		mu.Unlock()
+		return squareMapper(ctx, input)
+	}
+
+	stream := NewStream(context.Background(), mapper, WorkersOpt(4), RateLimitOpt(10, 1), RateLimitKeyOpt(key))
+
+	items, err := Collect(context.Background(), stream(&testIter{list: list[:8]}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 8, len(items); want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+
+	// every key is getting its own limit, so the 2 calls per key are 1 interval apart
+	for k, times := range calls {
+		if want, got := 2, len(times); want != got {
+			t.Fatalf("Expected %d calls for key %d, got %d", want, k, got)
+		}
+		if gap := times[1].Sub(times[0]); gap < 90*time.Millisecond {
+			t.Fatalf("Expected the calls for key %d to be at least 90ms apart, got %v", k, gap)
+		}
+	}
+}
+
+func TestRateLimitKeyPanic(t *testing.T) {
+
+	key := func(item int) int {
+		if item == 3 {
+			panic("no key")
+		}
+		return item
+	}
+
+	// the type mismatch of the key func is panicking as well
+	mismatch := func(item string) string {
+		return item
+	}
+
+	nop := func(_ context.Context, item int) (int, error) { return item, nil }
+
+	for testnr, opt := range []StreamOpt{RateLimitKeyOpt(key), RateLimitKeyOpt(mismatch)} {
+
+		stream := NewStream(context.Background(), nop, WorkersOpt(2), RateLimitOpt(1000, 10), opt)
+
+		_, err := Collect(context.Background(), stream(FromSlice(ints)))
+
+		var streamErr *StreamError
+		if !errors.As(err, &streamErr) {
+			t.Fatalf("test %d: Expected a StreamError, got %v", testnr, err)
+		}
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("test %d: Expected a *PanicError, got %v", testnr, err)
+		}
+	}
+}
+
+func TestRateLimitClose(t *testing.T) {
+
+	stream := NewStream(context.Background(), squareMapper, WorkersOpt(2), RateLimitOpt(0.1, 1))
+	iter := stream(&testIter{list: list})
+
+	if _, err := iter.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the workers are waiting 10s for the next token, but Close is unblocking them
+	start := time.Now()
+	iter.Close()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected Close to return immediately, took %v", elapsed)
+	}
+}
+
+func TestRateLimitWorkerFailed(t *testing.T) {
+
+	failing := func(_ context.Context, item int) (int, error) { return 0, errFive }
+
+	stream := NewStream(context.Background(), failing, WorkersOpt(3), RateLimitOpt(0.1, 1))
+	iter := stream(FromSlice(ints))
+	defer iter.Close()
+
+	// the other workers are waiting 10s for the next token, but the failure is unblocking them
+	start := time.Now()
+
+	if _, err := iter.Next(); !errors.Is(err, errFive) {
+		t.Fatalf("Expected errFive, got %v", err)
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the stream to fail immediately, took %v", elapsed)
+	}
+}
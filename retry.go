@@ -0,0 +1,73 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy is configuring the retries of failed Mapper calls, see RetryOpt.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of Mapper calls per item, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is capping the time to wait between retries (0: no limit).
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff is growing by after each retry (default: 2).
+	Multiplier float64
+	// Jitter is the fraction of the backoff which is randomized, between 0 and 1 (0: no jitter).
+	Jitter float64
+	// Retryable is classifying the errors which should be retried (default: all errors).
+	Retryable func(error) bool
+}
+
+// retryable is returning true if the given error of the given attempt should be retried.
+func (r *RetryPolicy) retryable(attempt int, err error) bool {
+	if r == nil || attempt >= r.MaxAttempts || err == io.EOF || errors.Is(err, ErrSkip) {
+		return false
+	}
+	return r.Retryable == nil || r.Retryable(err)
+}
+
+// maxDuration is the largest float64 value which can be converted to a time.Duration.
+var maxDuration = math.Nextafter(math.MaxInt64, 0)
+
+// backoff is returning the time to wait after the given failed attempt.
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := r.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	backoff := float64(r.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if r.MaxBackoff > 0 && backoff > float64(r.MaxBackoff) {
+		backoff = float64(r.MaxBackoff)
+	}
+
+	// without a max backoff, the growing backoff would overflow the duration after many attempts
+	if backoff > maxDuration {
+		backoff = maxDuration
+	}
+
+	backoff -= backoff * r.Jitter * rand.Float64()
+
+	return time.Duration(backoff)
+}
+
+// sleep is waiting for the given duration or until the context is done. It returns false if
+// the context is done.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,193 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+// flakyMapper is returning a mapper failing the given number of times for every item before succeeding.
+func flakyMapper(failures int, err error) Mapper {
+	mu := sync.Mutex{}
+	attempts := map[int]int{}
+
+	return func(ctx context.Context, input interface{}) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		in := input.(data)
+		attempts[in.input]++
+		if attempts[in.input] <= failures {
+			return nil, err
+		}
+		return squareMapper(ctx, input)
+	}
+}
+
+func TestRetry(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Microsecond, Jitter: 0.5}
+
+		stream := NewStream(context.Background(), flakyMapper(2, errFlaky),
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), RetryOpt(policy))
+
+		iter := stream(&testIter{list: list})
+		defer iter.Close()
+
+		for i := 0; i < len(list); i++ {
+			a, err := iter.Next()
+			if err != nil {
+				t.Fatalf("test %d, item %d: %v", testnr, i, err)
+			}
+			if want, got := a.(data).input*a.(data).input, a.(data).result; want != got {
+				t.Fatalf("test %d: Expected %d^2 = %d, got %d", testnr, a.(data).input, want, got)
+			}
+		}
+
+		if _, err := iter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Microsecond}
+
+	iter := NewStream(context.Background(), flakyMapper(3, errFlaky), RetryOpt(policy))(&testIter{list: list})
+	defer iter.Close()
+
+	_, err := iter.Next()
+
+	var streamErr *StreamError
+	if !errors.As(err, &streamErr) || !errors.Is(err, errFlaky) {
+		t.Fatalf("Expected a *StreamError wrapping errFlaky, got %v", err)
+	}
+
+	if want, got := 3, streamErr.Attempts; want != got {
+		t.Fatalf("Expected %d attempts, got %d", want, got)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Microsecond,
+		Retryable:      func(err error) bool { return !errors.Is(err, errFive) },
+	}
+
+	iter := NewStream(context.Background(), flakyMapper(1, errFive), RetryOpt(policy))(&testIter{list: list})
+	defer iter.Close()
+
+	_, err := iter.Next()
+
+	var streamErr *StreamError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Expected a *StreamError, got %v", err)
+	}
+
+	if want, got := 1, streamErr.Attempts; want != got {
+		t.Fatalf("Expected %d attempt for a non-retryable error, got %d", want, got)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+
+	policy := &RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	for attempt, want := range []time.Duration{0, 1, 2, 4, 5, 5} {
+		if attempt == 0 {
+			continue
+		}
+		if got := policy.backoff(attempt); want*time.Millisecond != got {
+			t.Fatalf("attempt %d: Expected backoff %v, got %v", attempt, want*time.Millisecond, got)
+		}
+	}
+}
+
+func TestRetryBackoffOverflow(t *testing.T) {
+
+	policy := &RetryPolicy{MaxAttempts: 1000, InitialBackoff: time.Second}
+
+	if got := policy.backoff(100); got <= 0 {
+		t.Fatalf("Expected a positive backoff without max backoff, got %v", got)
+	}
+}
+
+func TestRetryWorkerFailed(t *testing.T) {
+
+	var mu sync.Mutex
+	calls := 0
+
+	mapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+
+		if input.(data).input == 1 {
+			time.Sleep(10 * time.Millisecond)
+			return nil, errFive
+		}
+		return nil, errFlaky
+	}
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, Retryable: func(err error) bool { return errors.Is(err, errFlaky) }}
+
+	iter := NewStream(context.Background(), mapper, WorkersOpt(2), RetryOpt(policy))(&testIter{list: list})
+	defer iter.Close()
+
+	// the backoff of the other worker is canceled by the failure, so the stream is ending right away
+	eof := make(chan error)
+	go func() {
+		var err error
+		for err == nil || errors.Is(err, errFive) {
+			_, err = iter.Next()
+		}
+		eof <- err
+	}()
+
+	select {
+	case err := <-eof:
+		if err != io.EOF {
+			t.Fatalf("Expected io.EOF, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the failed stream to end without waiting for the backoff")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want, got := 2, calls; want != got {
+		t.Fatalf("Expected %d Mapper calls, got %d", want, got)
+	}
+}
+
+func TestRetryCanceledBackoff(t *testing.T) {
+
+	policy := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour}
+
+	iter := NewStream(context.Background(), flakyMapper(1, errFlaky), RetryOpt(policy))(&testIter{list: list})
+
+	// wait for the worker to start its backoff
+	time.Sleep(time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		iter.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to cancel the backoff")
+	}
+}
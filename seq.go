@@ -0,0 +1,106 @@
+package iter
+
+import (
+	"io"
+	goiter "iter"
+	"sync"
+)
+
+// Seq2 is returning a range-over-func sequence of the items and errors of the given TypedIterator.
+// The sequence is ending after io.EOF, other errors are yielded together with the zero value of T.
+// The iterator is closed when the sequence ends or the loop is left early.
+func Seq2[T any](it TypedIterator[T]) goiter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer it.Close()
+
+		for {
+			item, err := it.Next()
+			if err == io.EOF {
+				return
+			}
+
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// FromSeq is returning a TypedIterator for the items of the given sequence, which can be used
+// as input of a TypedStream. Close is stopping the sequence.
+func FromSeq[T any](seq goiter.Seq[T]) TypedIterator[T] {
+	return FromSeq2(func(yield func(T, error) bool) {
+		for item := range seq {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	})
+}
+
+// FromSeq2 is returning a TypedIterator for the items and errors of the given sequence, which can be used
+// as input of a TypedStream. Close is stopping the sequence.
+func FromSeq2[T any](seq goiter.Seq2[T, error]) TypedIterator[T] {
+	next, stop := goiter.Pull2(seq)
+	return &seqIterator[T]{next: next, stop: stop}
+}
+
+// seqIterator is implementing the TypedIterator interface for a pull-style sequence.
+// The sequence funcs are not safe for concurrent use, so they are guarded by a mutex.
+type seqIterator[T any] struct {
+	mu   sync.Mutex
+	next func() (T, error, bool)
+	stop func()
+}
+
+// Next is returning the next item of the sequence or io.EOF if the sequence is exhausted.
+func (i *seqIterator[T]) Next() (T, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	item, err, ok := i.next()
+	if !ok {
+		return item, io.EOF
+	}
+	return item, err
+}
+
+// Close is stopping the sequence.
+func (i *seqIterator[T]) Close() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.stop()
+}
+
+// FromSlice is returning a TypedIterator for the items of the given slice, which can be used
+// as input of a TypedStream.
+func FromSlice[T any](items []T) TypedIterator[T] {
+	return &sliceIterator[T]{items: items}
+}
+
+// sliceIterator is implementing the TypedIterator interface for a slice.
+type sliceIterator[T any] struct {
+	mu     sync.Mutex
+	items  []T
+	cursor int
+}
+
+// Next is returning the next item of the slice or io.EOF if all items were returned.
+func (i *sliceIterator[T]) Next() (T, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.cursor >= len(i.items) {
+		var zero T
+		return zero, io.EOF
+	}
+
+	item := i.items[i.cursor]
+	i.cursor++
+	return item, nil
+}
+
+// Close is a no-op.
+func (i *sliceIterator[T]) Close() {
+}
@@ -0,0 +1,134 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestSeq2(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		stream := NewStream(context.Background(), failingMapper,
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), ContOnErrOpt(true), OrderedOpt(true))
+
+		i := 0
+		for item, err := range Seq2(stream(&testIter{list: list})) {
+			if i == 4 {
+				if !errors.Is(err, errFive) {
+					t.Fatalf("test %d: Expected errFive, got %v", testnr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("test %d, item %d: %v", testnr, i, err)
+				}
+				if want, got := list[i].input, item.(data).input; want != got {
+					t.Fatalf("test %d: Expected item %d, got %d", testnr, want, got)
+				}
+			}
+			i++
+		}
+
+		if want, got := len(list), i; want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+	}
+}
+
+type closeRecorder struct {
+	TypedIterator[int]
+	closed bool
+}
+
+func (c *closeRecorder) Close() {
+	c.closed = true
+	c.TypedIterator.Close()
+}
+
+func TestSeq2Break(t *testing.T) {
+
+	it := &closeRecorder{TypedIterator: FromSeq(slices.Values([]int{1, 2, 3}))}
+
+	for item := range Seq2(it) {
+		if item == 2 {
+			break
+		}
+	}
+
+	if !it.closed {
+		t.Fatal("Expected iterator to be closed after break")
+	}
+}
+
+func TestFromSeq(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		square := func(_ context.Context, input int) (int, error) {
+			return input * input, nil
+		}
+
+		stream := NewStream(context.Background(), square, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers))
+
+		it := stream(FromSeq(slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8, 9})))
+		defer it.Close()
+
+		sum := 0
+		for j := 0; j < len(list); j++ {
+			res, err := it.Next()
+			if err != nil {
+				t.Fatalf("test %d, item %d: %v", testnr, j, err)
+			}
+			sum += res
+		}
+
+		if want, got := 285, sum; want != got {
+			t.Fatalf("test %d: Expected sum of squares %d, got %d", testnr, want, got)
+		}
+
+		if _, err := it.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+	}
+}
+
+func TestFromSeq2(t *testing.T) {
+
+	seq := func(yield func(int, error) bool) {
+		for i := 1; i <= 9; i++ {
+			var err error
+			if i == 5 {
+				err = errFive
+			}
+			if !yield(i, err) {
+				return
+			}
+		}
+	}
+
+	it := FromSeq2(seq)
+	defer it.Close()
+
+	for i := 1; i <= 9; i++ {
+		res, err := it.Next()
+		if i == 5 {
+			if !errors.Is(err, errFive) {
+				t.Fatalf("Expected errFive, got %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := i, res; want != got {
+			t.Fatalf("Expected item %d, got %d", want, got)
+		}
+	}
+
+	if _, err := it.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
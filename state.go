@@ -0,0 +1,55 @@
+package iter
+
+import "errors"
+
+// ErrClosed is the cancellation cause of a stream which was closed by the consumer.
+var ErrClosed = errors.New("iterator closed")
+
+// State is the state of a stream.
+type State int
+
+const (
+	// StateRunning is the state of a stream whose workers are running.
+	StateRunning State = iota
+	// StateDraining is the state of a stream whose workers have exited, but buffered results were not consumed yet.
+	StateDraining
+	// StateCompleted is the state of a stream which has processed all items of its source.
+	StateCompleted
+	// StateFailed is the state of a stream which was stopped by an error.
+	StateFailed
+	// StateCanceled is the state of a stream which was closed or whose context was canceled.
+	StateCanceled
+)
+
+// String is implementing the fmt.Stringer interface.
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateCompleted:
+		return "completed"
+	case StateFailed:
+		return "failed"
+	case StateCanceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// StreamIterator is the extended interface of the iterators returned by the stream constructors,
+// allowing to inspect the state of the stream.
+type StreamIterator[T any] interface {
+	TypedIterator[T]
+	CloseWaiter
+
+	// Err is returning the error which stopped the stream: the first fatal error of a worker or
+	// the cause of the cancellation (see context.Cause). It is nil while the stream is running
+	// and after it completed. Errors skipped with continue-on-error are not reported.
+	Err() error
+	// Done is returning a channel which is closed after all goroutines of the stream have exited.
+	Done() <-chan struct{}
+	// State is returning the current state of the stream.
+	State() State
+}
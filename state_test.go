@@ -0,0 +1,102 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestStateCompleted(t *testing.T) {
+
+	iter := NewStream(context.Background(), squareMapper, BufSizeOpt(len(list)))(&testIter{list: list}).(StreamIterator[interface{}])
+	defer iter.Close()
+
+	<-iter.Done()
+
+	// all results are buffered, but not consumed yet
+	if want, got := StateDraining, iter.State(); want != got {
+		t.Fatalf("Expected state %v, got %v", want, got)
+	}
+
+	for {
+		if _, err := iter.Next(); err == io.EOF {
+			break
+		}
+	}
+
+	if want, got := StateCompleted, iter.State(); want != got {
+		t.Fatalf("Expected state %v, got %v", want, got)
+	}
+
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestStateFailed(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		stream := NewStream(context.Background(), failingMapper, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers))
+
+		iter := stream(&testIter{list: list}).(StreamIterator[interface{}])
+		defer iter.Close()
+
+		if want, got := StateRunning, iter.State(); want != got {
+			t.Fatalf("test %d: Expected state %v, got %v", testnr, want, got)
+		}
+
+		for {
+			if _, err := iter.Next(); err == io.EOF {
+				break
+			}
+		}
+
+		<-iter.Done()
+
+		if want, got := StateFailed, iter.State(); want != got {
+			t.Fatalf("test %d: Expected state %v, got %v", testnr, want, got)
+		}
+
+		if err := iter.Err(); !errors.Is(err, errFive) {
+			t.Fatalf("test %d: Expected errFive, got %v", testnr, err)
+		}
+	}
+}
+
+func TestStateCanceled(t *testing.T) {
+
+	generator := func() (interface{}, error) {
+		return data{input: 1}, nil
+	}
+
+	iter := NewGeneratorStream(context.Background(), squareMapper)(generator).(StreamIterator[interface{}])
+	iter.Close()
+
+	if want, got := StateCanceled, iter.State(); want != got {
+		t.Fatalf("Expected state %v, got %v", want, got)
+	}
+
+	if err := iter.Err(); err != ErrClosed {
+		t.Fatalf("Expected ErrClosed, got %v", err)
+	}
+
+	// the cause of the canceled parent context is reported
+	errShutdown := errors.New("shutdown")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	iter = NewGeneratorStream(ctx, squareMapper)(generator).(StreamIterator[interface{}])
+	defer iter.Close()
+
+	cancel(errShutdown)
+	<-iter.Done()
+
+	if want, got := StateCanceled, iter.State(); want != got {
+		t.Fatalf("Expected state %v, got %v", want, got)
+	}
+
+	if err := iter.Err(); err != errShutdown {
+		t.Fatalf("Expected errShutdown, got %v", err)
+	}
+}
@@ -3,43 +3,110 @@ package iter
 import (
 	"context"
 	"fmt"
+	"hash/maphash"
+	"time"
 )
 
-// Mapper is the signature of a mapper function which is applied to the stream items by the worker go routines.
-type Mapper func(ctx context.Context, input interface{}) (outpout interface{}, err error)
+// TypedMapper is the signature of a mapper function which is applied to the stream items by the worker go routines.
+// It is mapping an input item of type In to an output item of type Out.
+type TypedMapper[In, Out any] func(ctx context.Context, input In) (output Out, err error)
 
-// Stream is the signature of a function that creates a new Iterator for processing and streaming of data
-// from the given Iterator.
-type Stream func(Iterator) Iterator
+// Mapper is the untyped version of TypedMapper, working on items of type interface{}.
+type Mapper = TypedMapper[interface{}, interface{}]
 
-// NewStream is setting up a Stream func with the given Mapper func and StreamOpts.
-func NewStream(ctx context.Context, mapper Mapper, opts ...StreamOpt) Stream {
+// TypedStream is the signature of a function that creates a new TypedIterator for processing and streaming of data
+// from the given TypedIterator.
+type TypedStream[In, Out any] func(TypedIterator[In]) TypedIterator[Out]
 
-	return func(inIter Iterator) Iterator {
+// Stream is the untyped version of TypedStream, working on items of type interface{}.
+type Stream = TypedStream[interface{}, interface{}]
 
-		// We wrap the given Iterator in a closure with Generator func signature.
-		generator := func() (interface{}, error) {
-			return inIter.Next()
-		}
+// NewStream is setting up a TypedStream func with the given TypedMapper func and StreamOpts.
+// Each call of the returned func is starting an independent stream, so it can be used as a template.
+// The stream is owning its input iterator and closes it when the stream is closed or finished,
+// unless configured otherwise with CloseInputOpt.
+func NewStream[In, Out any](ctx context.Context, mapper TypedMapper[In, Out], opts ...StreamOpt) TypedStream[In, Out] {
+
+	cfg := newStreamConf(opts...)
+
+	return func(inIter TypedIterator[In]) TypedIterator[Out] {
+		return startIterStream(ctx, cfg, mapper, inIter)
+	}
+}
 
-		// Now we can implement NewStream by calling NewGeneratorStream.
-		return NewGeneratorStream(ctx, mapper, opts...)(generator)
+// startIterStream is starting a stream for the given input iterator.
+func startIterStream[In, Out any](ctx context.Context, cfg *streamConf, mapper TypedMapper[In, Out], inIter TypedIterator[In]) StreamIterator[Out] {
+
+	// We wrap the given Iterator in a closure with Generator func signature.
+	generator := func() (In, error) {
+		return inIter.Next()
+	}
+
+	var inputCloser func()
+	if cfg.CloseInput {
+		inputCloser = inIter.Close
 	}
+
+	// Now we can implement NewStream by starting a generator stream.
+	return startStream(ctx, cfg, mapper, generator, inputCloser)
 }
 
 type streamConf struct {
 	BufSize         int
 	Workers         int
 	ContinueOnError bool
+	Ordered         bool
+	ReorderWindow   int
+	CloseInput      bool
+	Name            string
+	DeadLetters     DeadLetterSink
+	Retry           *RetryPolicy
+	ItemTimeout     time.Duration
+	Repanic         bool
+
+	// Lane is assigning an item to one of n worker lanes in keyed mode.
+	Lane func(item interface{}, n int) int
+
+	Rate  float64
+	Burst int
+	// RateKey is returning the key of an item for per-key rate limits.
+	RateKey func(item interface{}) interface{}
+
+	Adaptive *AdaptivePolicy
+	Control  *Control
 }
 
-// newStreamConf is creating  a default stream config.
-func newStreamConf() *streamConf {
-	return &streamConf{
+// newStreamConf is creating  a default stream config and applies the given StreamOpts.
+func newStreamConf(opts ...StreamOpt) *streamConf {
+	conf := &streamConf{
 		Workers:         1,
 		BufSize:         0,
 		ContinueOnError: false,
+		Ordered:         false,
+		ReorderWindow:   0,
+		CloseInput:      true,
+		Name:            "",
+		DeadLetters:     nil,
+		Retry:           nil,
+		ItemTimeout:     0,
+		Repanic:         false,
+		Lane:            nil,
+		Rate:            0,
+		Burst:           0,
+		RateKey:         nil,
+		Adaptive:        nil,
+		Control:         nil,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	if conf.Adaptive != nil {
+		conf.Workers = conf.Adaptive.Max
+	}
+	if conf.Ordered && conf.Lane != nil {
+		panic("ordered and keyed mode can't be combined")
 	}
+	return conf
 }
 
 // StreamOpt is a functional option type.
@@ -68,3 +135,147 @@ func ContOnErrOpt(cont bool) StreamOpt {
 		conf.ContinueOnError = cont
 	}
 }
+
+// OrderedOpt is a functional option that lets the stream deliver the results in the order the input items
+// were pulled from the source, even when using multiple workers (default: false).
+// The source is called sequentially in ordered mode.
+func OrderedOpt(ordered bool) StreamOpt {
+	return func(conf *streamConf) {
+		conf.Ordered = ordered
+	}
+}
+
+// ReorderWindowOpt is a functional option setting the max amount of items in flight in ordered mode
+// (default: 2 * workers). A slow item is stalling the other workers when the window is exhausted.
+func ReorderWindowOpt(size int) StreamOpt {
+	if size < 1 {
+		panic(fmt.Sprintf("reorder window size: %d - need a window of at least 1 item", size))
+	}
+	return func(conf *streamConf) {
+		conf.ReorderWindow = size
+	}
+}
+
+// CloseInputOpt is a functional option that lets a stream close its input iterator when the stream
+// is closed or finished (default: true). Disable it if the input iterator is shared with other consumers.
+// Without closing the input, nothing is unblocking a worker waiting in the Next method of the input, so
+// Close is blocking until that call returns - use CloseWait to bound the time to wait.
+// Streams reading from channels are always stopping to read from them when closed.
+func CloseInputOpt(close bool) StreamOpt {
+	return func(conf *streamConf) {
+		conf.CloseInput = close
+	}
+}
+
+// NameOpt is a functional option setting the name of the stream, which is reported as stage
+// of a StreamError (default: "").
+func NameOpt(name string) StreamOpt {
+	return func(conf *streamConf) {
+		conf.Name = name
+	}
+}
+
+// DeadLetterOpt is a functional option routing failed items to the given sink (default: nil).
+// With continue-on-error, the iterator of the stream is only returning successfully processed items.
+// Otherwise the error is also returned by the iterator and the stream is stopping as usual.
+// An error of the sink is returned by the iterator and stops the stream.
+func DeadLetterOpt(sink DeadLetterSink) StreamOpt {
+	return func(conf *streamConf) {
+		conf.DeadLetters = sink
+	}
+}
+
+// RetryOpt is a functional option letting the workers retry failed Mapper calls according to the given
+// policy (default: no retries). The workers are waiting with exponential backoff between the attempts.
+// The number of attempts is reported in the StreamError of an item which failed finally.
+func RetryOpt(policy RetryPolicy) StreamOpt {
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		panic(fmt.Sprintf("retry jitter: %f - needs to be between 0 and 1", policy.Jitter))
+	}
+	return func(conf *streamConf) {
+		conf.Retry = &policy
+	}
+}
+
+// ItemTimeoutOpt is a functional option setting a deadline for every Mapper call (default: 0 - no deadline).
+// The deadline is set on the context passed to the Mapper, which needs to respect it. The error of a
+// Mapper call exceeding the deadline is wrapping ErrItemTimeout. When retrying, every attempt is
+// getting a new deadline.
+func ItemTimeoutOpt(d time.Duration) StreamOpt {
+	return func(conf *streamConf) {
+		conf.ItemTimeout = d
+	}
+}
+
+// RepanicOpt is a functional option letting panics of Generator and Mapper funcs crash the program,
+// e.g. for debugging (default: false). Otherwise a panic is recovered by the worker and reported as a
+// PanicError, which is handled like any other error of the stream.
+func RepanicOpt(repanic bool) StreamOpt {
+	return func(conf *streamConf) {
+		conf.Repanic = repanic
+	}
+}
+
+// KeyedOpt is a functional option assigning the input items to worker lanes by the hash of the key returned
+// by the given key func (default: nil). Items with the same key are processed sequentially by the same
+// worker in the order they were pulled from the source, while items with different keys are processed in
+// parallel. A slow lane is stalling the dispatch of further items when its buffer is full.
+// The type In needs to match the input type of the stream, a mismatch or a panicking key func is failing
+// the item with a PanicError. Keyed mode can't be combined with OrderedOpt.
+func KeyedOpt[In any, K comparable](key func(item In) K) StreamOpt {
+	seed := maphash.MakeSeed()
+
+	return func(conf *streamConf) {
+		conf.Lane = func(item interface{}, n int) int {
+			return int(maphash.Comparable(seed, key(item.(In))) % uint64(n))
+		}
+	}
+}
+
+// RateLimitOpt is a functional option limiting the Mapper calls of all workers of a stream together to the
+// given rate per second, allowing bursts of up to burst calls (default: 0 - no limit). Every attempt of a
+// retried Mapper call is counted. Workers waiting for the rate limit are unblocked when the stream is closed
+// or failed.
+// Every invocation of a stream func is getting its own limiter.
+func RateLimitOpt(rate float64, burst int) StreamOpt {
+	if rate <= 0 || burst < 1 {
+		panic(fmt.Sprintf("rate limit: %f, burst: %d - need a positive rate and a burst of at least 1", rate, burst))
+	}
+	return func(conf *streamConf) {
+		conf.Rate = rate
+		conf.Burst = burst
+	}
+}
+
+// RateLimitKeyOpt is a functional option applying the rate limit set with RateLimitOpt per key, as returned
+// by the given key func for every input item, e.g. for per-tenant limits (default: nil - one limit for all items).
+// The type In needs to match the input type of the stream, a mismatch or a panicking key func is failing
+// the item with a PanicError.
+func RateLimitKeyOpt[In any, K comparable](key func(item In) K) StreamOpt {
+	return func(conf *streamConf) {
+		conf.RateKey = func(item interface{}) interface{} {
+			return key(item.(In))
+		}
+	}
+}
+
+// AdaptiveWorkersOpt is a functional option adapting the number of concurrent Mapper calls between the Min and
+// Max of the given policy to the observed latency and errors of the Mapper calls (default: nil - a fixed
+// number of workers). Max worker goroutines are started, overriding WorkersOpt, but only as many of them as
+// the current limit are calling the Mapper at the same time. Every invocation of a stream func is getting
+// its own limit.
+func AdaptiveWorkersOpt(policy AdaptivePolicy) StreamOpt {
+	policy.validate()
+
+	return func(conf *streamConf) {
+		conf.Adaptive = &policy
+	}
+}
+
+// ControlOpt is a functional option attaching the streams to the given Control, which allows changing the
+// number of workers and continue-on-error, and pausing the streams at runtime (default: nil).
+func ControlOpt(control *Control) StreamOpt {
+	return func(conf *streamConf) {
+		conf.Control = control
+	}
+}
@@ -3,7 +3,9 @@ package iter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -150,7 +152,7 @@ func TestStreamerInjectFailure(t *testing.T) {
 			_, err := outIter.Next()
 
 			if err != nil {
-				if err == errFive {
+				if errors.Is(err, errFive) {
 					break
 				}
 				t.Fatalf("test %d: Expected worker to fail for input 5 but got err %v", testnr, err)
@@ -261,7 +263,7 @@ func TestChannelStreamerInjectFailure(t *testing.T) {
 			_, err := outIter.Next()
 
 			if err != nil {
-				if err == errFive {
+				if errors.Is(err, errFive) {
 					break
 				}
 				t.Fatalf("test %d: Expected worker to fail for input 5 but got err %v", testnr, err)
@@ -305,7 +307,7 @@ func TestGeneratorStreamer(t *testing.T) {
 		for ; j < len(list); j++ {
 			_, err := iter.Next()
 			if err != nil {
-				if err == errFive {
+				if errors.Is(err, errFive) {
 					break
 				}
 				t.Fatalf("test %d: Expected worker to fail for input 5 but got err %v", testnr, err)
@@ -354,7 +356,7 @@ func TestChaining(t *testing.T) {
 		for ; j < len(list); j++ {
 			_, err := iter.Next()
 			if err != nil {
-				if err == errFive {
+				if errors.Is(err, errFive) {
 					break
 				}
 				t.Fatalf("test %d: Expected worker to fail for input 5 but got err %v", testnr, err)
@@ -414,7 +416,7 @@ func TestContOnError(t *testing.T) {
 		for ; j < len(list); j++ {
 			a, err := iter.Next()
 			if err != nil {
-				if err == errFive {
+				if errors.Is(err, errFive) {
 					continue
 				}
 				t.Fatalf("test %d: Expected worker to fail for input 5 but got err %v", testnr, err)
@@ -434,3 +436,588 @@ func TestContOnError(t *testing.T) {
 		}
 	}
 }
+
+func TestTypedStreamer(t *testing.T) {
+
+	for testnr, parms := range testCases {
+		mu := &sync.Mutex{}
+		i := 0
+
+		generator := func() (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if i >= len(list) {
+				return 0, io.EOF
+			}
+			i++
+			return list[i-1].input, nil
+		}
+
+		square := func(_ context.Context, input int) (data, error) {
+			return data{input: input, result: input * input}, nil
+		}
+
+		result := func(_ context.Context, input data) (int, error) {
+			return input.result, nil
+		}
+
+		ctx := context.Background()
+		opts := []StreamOpt{BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers)}
+
+		// the types of the chained streams are checked at compile time
+		var iter TypedIterator[int] = NewStream(ctx, result, opts...)(NewGeneratorStream(ctx, square, opts...)(generator))
+		defer iter.Close()
+
+		sum := 0
+		for j := 0; j < len(list); j++ {
+			res, err := iter.Next()
+			if err != nil {
+				t.Fatalf("test %d, item %d: %v", testnr, j, err)
+			}
+			sum += res
+		}
+
+		if want, got := 285, sum; want != got {
+			t.Fatalf("test %d: Expected sum of squares %d, got %d", testnr, want, got)
+		}
+
+		if _, err := iter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v", testnr, err)
+		}
+	}
+}
+
+// slowMapper is squaring the input after sleeping a time depending on the input, so results
+// of multiple workers are finished out of order.
+func slowMapper(ctx context.Context, input interface{}) (output interface{}, err error) {
+	time.Sleep(time.Duration(10-input.(data).input) * 100 * time.Microsecond)
+	return failingMapper(ctx, input)
+}
+
+func TestOrdered(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		inIter := &testIter{list: list}
+
+		stream := NewStream(context.Background(), slowMapper, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true))
+
+		outIter := stream(inIter)
+		defer outIter.Close()
+
+		for i := 0; i < 4; i++ {
+			a, err := outIter.Next()
+			if err != nil {
+				t.Fatalf("test %d, item %d: %v", testnr, i, err)
+			}
+
+			if want, got := list[i].input, a.(data).input; want != got {
+				t.Fatalf("test %d: Expected item %d, got %d", testnr, want, got)
+			}
+		}
+
+		if _, err := outIter.Next(); !errors.Is(err, errFive) {
+			t.Fatalf("test %d: Expected errFive, got %v", testnr, err)
+		}
+
+		// in ordered mode no results are delivered after the error
+		if a, err := outIter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v, %v", testnr, a, err)
+		}
+	}
+}
+
+func TestOrderedContOnError(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		inIter := &testIter{list: list}
+
+		stream := NewStream(context.Background(), slowMapper,
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true), ContOnErrOpt(true))
+
+		outIter := stream(inIter)
+		defer outIter.Close()
+
+		for i := 0; i < len(list); i++ {
+			a, err := outIter.Next()
+			if i == 4 {
+				if !errors.Is(err, errFive) {
+					t.Fatalf("test %d: Expected errFive, got %v", testnr, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("test %d, item %d: %v", testnr, i, err)
+			}
+
+			if want, got := list[i].input, a.(data).input; want != got {
+				t.Fatalf("test %d: Expected item %d, got %d", testnr, want, got)
+			}
+		}
+
+		if a, err := outIter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF: %v, %v", testnr, a, err)
+		}
+	}
+}
+
+func TestOrderedMapperEOF(t *testing.T) {
+
+	eofMapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+		if input.(data).input == 5 {
+			return nil, io.EOF
+		}
+		return squareMapper(ctx, input)
+	}
+
+	// a Mapper returning io.EOF is stopping its worker in both modes, without failing the stream
+	for testnr, ordered := range []bool{false, true} {
+
+		iter := NewStream(context.Background(), eofMapper, OrderedOpt(ordered))(&testIter{list: list}).(StreamIterator[interface{}])
+
+		items, err := Collect[interface{}](context.Background(), iter)
+		if err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		if want, got := 4, len(items); want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+
+		if want, got := StateCompleted, iter.State(); want != got {
+			t.Fatalf("test %d: Expected state %v, got %v: %v", testnr, want, got, iter.Err())
+		}
+	}
+}
+
+func TestReorderWindow(t *testing.T) {
+
+	mu := &sync.Mutex{}
+	pulled := 0
+	release := make(chan struct{})
+
+	generator := func() (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		pulled++
+		return pulled, nil
+	}
+
+	// the first item is blocking until released, stalling the stream when the window is exhausted
+	mapper := func(_ context.Context, input int) (int, error) {
+		if input == 1 {
+			<-release
+		}
+		return input, nil
+	}
+
+	iter := NewGeneratorStream(context.Background(), mapper, WorkersOpt(5), OrderedOpt(true), ReorderWindowOpt(2))(generator)
+	defer iter.Close()
+
+	time.Sleep(10 * time.Millisecond)
+
+	mu.Lock()
+	if want, got := 2, pulled; want != got {
+		t.Fatalf("Expected %d pulled items, got %d", want, got)
+	}
+	mu.Unlock()
+
+	close(release)
+
+	for i := 1; i <= 20; i++ {
+		res, err := iter.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := i, res; want != got {
+			t.Fatalf("Expected item %d, got %d", want, got)
+		}
+	}
+}
+
+func TestCloseWaitsForWorkers(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		mu := &sync.Mutex{}
+		active := 0
+		calls := 0
+
+		mapper := func(_ context.Context, input interface{}) (interface{}, error) {
+			mu.Lock()
+			active++
+			calls++
+			mu.Unlock()
+
+			time.Sleep(100 * time.Microsecond)
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return input, nil
+		}
+
+		generator := func() (interface{}, error) {
+			return 1, nil
+		}
+
+		iter := NewGeneratorStream(context.Background(), mapper, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers))(generator)
+
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		iter.Close()
+		iter.Close()
+
+		mu.Lock()
+		if active != 0 {
+			t.Fatalf("test %d: Expected no active mapper after Close, got %d", testnr, active)
+		}
+		callsAfterClose := calls
+		mu.Unlock()
+
+		if _, err := iter.Next(); err != io.EOF {
+			t.Fatalf("test %d: Expected io.EOF after Close: %v", testnr, err)
+		}
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		if want, got := callsAfterClose, calls; want != got {
+			t.Fatalf("test %d: Expected %d mapper calls, got %d", testnr, want, got)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestCloseWait(t *testing.T) {
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// a mapper ignoring the cancellation of its context
+	stuckMapper := func(_ context.Context, input interface{}) (interface{}, error) {
+		<-release
+		return input, nil
+	}
+
+	iter := NewStream(context.Background(), stuckMapper)(&testIter{list: list})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err := iter.(CloseWaiter).CloseWait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestCloseInput(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		square := func(_ context.Context, input int) (int, error) {
+			return input * input, nil
+		}
+
+		opts := []StreamOpt{BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers)}
+
+		// closing the output iterator is closing the input iterator
+		in := &closeRecorder{TypedIterator: FromSeq(slices.Values([]int{1, 2, 3, 4}))}
+		out := NewStream(context.Background(), square, opts...)(in)
+
+		if _, err := out.Next(); err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+		out.Close()
+
+		if !in.closed {
+			t.Fatalf("test %d: Expected input to be closed after Close", testnr)
+		}
+
+		// reaching EOF is closing the input iterator
+		in = &closeRecorder{TypedIterator: FromSeq(slices.Values([]int{1, 2, 3, 4}))}
+		out = NewStream(context.Background(), square, opts...)(in)
+
+		for {
+			if _, err := out.Next(); err == io.EOF {
+				break
+			}
+		}
+
+		if !in.closed {
+			t.Fatalf("test %d: Expected input to be closed after EOF", testnr)
+		}
+
+		// shared inputs are not closed
+		in = &closeRecorder{TypedIterator: FromSeq(slices.Values([]int{1, 2, 3, 4}))}
+		out = NewStream(context.Background(), square, append(opts, CloseInputOpt(false))...)(in)
+		out.Close()
+
+		if in.closed {
+			t.Fatalf("test %d: Expected shared input not to be closed", testnr)
+		}
+		in.TypedIterator.Close()
+	}
+}
+
+func TestChainingCloseInput(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		mu := &sync.Mutex{}
+		calls := 0
+
+		generator := func() (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return data{input: calls}, nil
+		}
+
+		ctx := context.Background()
+		opts := []StreamOpt{BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers)}
+
+		iter := NewStream(ctx, squareMapper, opts...)(NewStream(ctx, nopMapper, opts...)(NewGeneratorStream(ctx, nopMapper, opts...)(generator)))
+
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		iter.Close()
+
+		mu.Lock()
+		callsAfterClose := calls
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		if want, got := callsAfterClose, calls; want != got {
+			t.Fatalf("test %d: Expected the generator not to be called after Close, got %d calls instead of %d", testnr, got, want)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestChannelStreamerCloseIdleInput(t *testing.T) {
+
+	inputChan := make(chan interface{})
+	errChan := make(chan error)
+
+	iter := NewChannelStream(context.Background(), squareMapper)(inputChan, errChan)
+
+	closed := make(chan struct{})
+	go func() {
+		iter.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close not to block on idle input channels")
+	}
+}
+
+func TestStreamTemplate(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		ctx := context.Background()
+		opts := []StreamOpt{BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers)}
+
+		genStream := NewGeneratorStream(ctx, squareMapper, opts...)
+		stream := NewStream(ctx, squareMapper, opts...)
+		chanStream := NewChannelStream(ctx, squareMapper, opts...)
+
+		wg := sync.WaitGroup{}
+
+		// every invocation of a stream func is starting an independent stream
+		for k := 0; k < 3; k++ {
+			inputChan := make(chan interface{})
+			go func() {
+				for _, i := range list {
+					inputChan <- i
+				}
+				close(inputChan)
+			}()
+
+			iters := []Iterator{
+				genStream((&testIter{list: list}).Next),
+				stream(&testIter{list: list}),
+				chanStream(inputChan, make(chan error)),
+			}
+
+			for _, iter := range iters {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer iter.Close()
+
+					n := 0
+					for {
+						a, err := iter.Next()
+						if err == io.EOF {
+							break
+						}
+						if err != nil {
+							t.Errorf("test %d: %v", testnr, err)
+							return
+						}
+						if want, got := a.(data).input*a.(data).input, a.(data).result; want != got {
+							t.Errorf("test %d: Expected %d^2 = %d, got %d", testnr, a.(data).input, want, got)
+						}
+						n++
+					}
+
+					if want, got := len(list), n; want != got {
+						t.Errorf("test %d: Expected %d items, got %d", testnr, want, got)
+					}
+				}()
+			}
+		}
+
+		wg.Wait()
+	}
+}
+
+// hangingMapper is returning a mapper blocking until its context is done for the given input.
+func hangingMapper(hangOn int) Mapper {
+	return func(ctx context.Context, input interface{}) (interface{}, error) {
+		if input.(data).input == hangOn {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return squareMapper(ctx, input)
+	}
+}
+
+func TestItemTimeout(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		stream := NewStream(context.Background(), hangingMapper(5),
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), ContOnErrOpt(true), ItemTimeoutOpt(time.Millisecond))
+
+		iter := stream(&testIter{list: list})
+		defer iter.Close()
+
+		n := 0
+		for {
+			_, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				if !errors.Is(err, ErrItemTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("test %d: Expected an item timeout, got %v", testnr, err)
+				}
+				continue
+			}
+			n++
+		}
+
+		if want, got := len(list)-1, n; want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+	}
+}
+
+func TestItemTimeoutRetry(t *testing.T) {
+
+	mu := sync.Mutex{}
+	calls := 0
+
+	// only the first call is hanging
+	mapper := func(ctx context.Context, input interface{}) (interface{}, error) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		if first {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return squareMapper(ctx, input)
+	}
+
+	policy := RetryPolicy{MaxAttempts: 2, Retryable: func(err error) bool { return errors.Is(err, ErrItemTimeout) }}
+
+	iter := NewStream(context.Background(), mapper, ItemTimeoutOpt(time.Millisecond), RetryOpt(policy))(&testIter{list: list})
+	defer iter.Close()
+
+	for i := 0; i < len(list); i++ {
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("item %d: %v", i, err)
+		}
+	}
+}
+
+func TestKeyed(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		mu := &sync.Mutex{}
+		active := map[int]int{}
+
+		// items with the same key must never be processed concurrently
+		mapper := func(ctx context.Context, input int) (int, error) {
+			key := input % 3
+
+			mu.Lock()
+			active[key]++
+			concurrent := active[key] > 1
+			mu.Unlock()
+
+			if concurrent {
+				return 0, fmt.Errorf("key %d processed concurrently", key)
+			}
+
+			time.Sleep(time.Duration(10-input%10) * 20 * time.Microsecond)
+
+			mu.Lock()
+			active[key]--
+			mu.Unlock()
+			return input, nil
+		}
+
+		key := func(item int) int {
+			return item % 3
+		}
+
+		items := make([]int, 30)
+		for i := range items {
+			items[i] = i
+		}
+
+		stream := NewStream(context.Background(), mapper, BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), KeyedOpt(key))
+
+		iter := stream(FromSlice(items))
+		defer iter.Close()
+
+		last := map[int]int{0: -1, 1: -1, 2: -1}
+		n := 0
+		for {
+			item, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("test %d: %v", testnr, err)
+			}
+			if item < last[key(item)] {
+				t.Fatalf("test %d: Expected items of key %d in order, got %d after %d", testnr, key(item), item, last[key(item)])
+			}
+			last[key(item)] = item
+			n++
+		}
+
+		if want, got := len(items), n; want != got {
+			t.Fatalf("test %d: Expected %d items, got %d", testnr, want, got)
+		}
+	}
+}
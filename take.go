@@ -0,0 +1,145 @@
+package iter
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Take is returning a TypedIterator for the first n items of the given iterator. The given iterator
+// is closed right after returning the n-th item, so no further items are pulled or processed upstream.
+// Errors of the given iterator are passed through and are not counted as items.
+func Take[T any](it TypedIterator[T], n int) TypedIterator[T] {
+	return &takeIterator[T]{in: it, remaining: n}
+}
+
+// TakeWhile is returning a TypedIterator for the items of the given iterator as long as they are matching
+// the given TypedPredicate. The given iterator is closed as soon as an item is not matching or the
+// predicate is failing. Errors of the given iterator are passed through.
+func TakeWhile[T any](ctx context.Context, it TypedIterator[T], predicate TypedPredicate[T]) TypedIterator[T] {
+	return &takeIterator[T]{ctx: ctx, in: it, remaining: -1, predicate: predicate}
+}
+
+// takeIterator is implementing the TypedIterator interface for Take and TakeWhile.
+type takeIterator[T any] struct {
+	// mu is serializing the calls of Next, it is not held by Close.
+	mu        sync.Mutex
+	ctx       context.Context
+	in        TypedIterator[T]
+	remaining int
+	predicate TypedPredicate[T]
+
+	closed    atomic.Bool
+	closeOnce sync.Once
+}
+
+// Next is returning the next item of the given iterator, or io.EOF after the limit is reached.
+func (i *takeIterator[T]) Next() (T, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var zero T
+
+	if i.remaining == 0 {
+		i.Close()
+	}
+	if i.closed.Load() {
+		return zero, io.EOF
+	}
+
+	item, err := i.in.Next()
+	if err == io.EOF {
+		i.Close()
+		return zero, io.EOF
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	if i.predicate != nil {
+		match, err := i.predicate(i.ctx, item)
+		if err != nil || !match {
+			i.Close()
+			if err == nil {
+				err = io.EOF
+			}
+			return zero, err
+		}
+		return item, nil
+	}
+
+	i.remaining--
+	if i.remaining == 0 {
+		i.Close()
+	}
+
+	return item, nil
+}
+
+// Close is closing the given iterator, which is unblocking a pending call of Next.
+func (i *takeIterator[T]) Close() {
+	i.closeOnce.Do(func() {
+		i.closed.Store(true)
+		i.in.Close()
+	})
+}
+
+// Skip is returning a TypedIterator for the items of the given iterator after skipping the first n items.
+// Errors of the given iterator are passed through and are not counted as items.
+func Skip[T any](it TypedIterator[T], n int) TypedIterator[T] {
+	return &skipIterator[T]{in: it, remaining: n}
+}
+
+// SkipWhile is returning a TypedIterator for the items of the given iterator after skipping the items
+// matching the given TypedPredicate, starting with the first item not matching.
+func SkipWhile[T any](ctx context.Context, it TypedIterator[T], predicate TypedPredicate[T]) TypedIterator[T] {
+	return &skipIterator[T]{ctx: ctx, in: it, remaining: -1, predicate: predicate}
+}
+
+// skipIterator is implementing the TypedIterator interface for Skip and SkipWhile.
+type skipIterator[T any] struct {
+	mu        sync.Mutex
+	ctx       context.Context
+	in        TypedIterator[T]
+	remaining int
+	predicate TypedPredicate[T]
+}
+
+// Next is returning the next item of the given iterator which is not skipped.
+func (i *skipIterator[T]) Next() (T, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for {
+		item, err := i.in.Next()
+		if err != nil {
+			return item, err
+		}
+
+		if i.predicate != nil {
+			skip, err := i.predicate(i.ctx, item)
+			if err != nil {
+				var zero T
+				return zero, err
+			}
+			if skip {
+				continue
+			}
+			i.predicate = nil
+			return item, nil
+		}
+
+		if i.remaining > 0 {
+			i.remaining--
+			continue
+		}
+
+		return item, nil
+	}
+}
+
+// Close is closing the given iterator.
+func (i *skipIterator[T]) Close() {
+	i.in.Close()
+}
@@ -0,0 +1,125 @@
+package iter
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// countingInts is returning an iterator for ints which is counting the pulled items.
+func countingInts(pulled *int) *closeRecorder {
+	return &closeRecorder{TypedIterator: FromSeq(func(yield func(int) bool) {
+		for _, i := range ints {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	})}
+}
+
+func TestTake(t *testing.T) {
+
+	ctx := context.Background()
+	lessThan := func(n int) TypedPredicate[int] {
+		return func(_ context.Context, item int) (bool, error) { return item < n, nil }
+	}
+
+	testCases := []struct {
+		take   func(TypedIterator[int]) TypedIterator[int]
+		want   []int
+		pulled int
+		early  bool
+	}{
+		{take: func(it TypedIterator[int]) TypedIterator[int] { return Take(it, 3) }, want: []int{1, 2, 3}, pulled: 3, early: true},
+		{take: func(it TypedIterator[int]) TypedIterator[int] { return Take(it, 0) }, want: []int{}, pulled: 0},
+		{take: func(it TypedIterator[int]) TypedIterator[int] { return Take(it, 20) }, want: ints, pulled: 9},
+		{take: func(it TypedIterator[int]) TypedIterator[int] { return TakeWhile(ctx, it, lessThan(4)) }, want: []int{1, 2, 3}, pulled: 4},
+	}
+
+	for testnr, parms := range testCases {
+
+		pulled := 0
+		in := countingInts(&pulled)
+		iter := parms.take(in)
+
+		items := []int{}
+		for {
+			item, err := iter.Next()
+			if err != nil {
+				break
+			}
+			items = append(items, item)
+
+			// Take is closing the input right after the last item, without pulling another one
+			if parms.early && len(items) == len(parms.want) && !in.closed {
+				t.Fatalf("test %d: Expected input to be closed after %d items", testnr, len(items))
+			}
+		}
+
+		if want, got := parms.want, items; !slices.Equal(want, got) {
+			t.Fatalf("test %d: Expected items %v, got %v", testnr, want, got)
+		}
+
+		if want, got := parms.pulled, pulled; want != got {
+			t.Fatalf("test %d: Expected %d pulled items, got %d", testnr, want, got)
+		}
+
+		if !in.closed {
+			t.Fatalf("test %d: Expected input to be closed", testnr)
+		}
+	}
+}
+
+func TestTakeStream(t *testing.T) {
+
+	calls := 0
+	generator := func() (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	// the stream is closed after the limit, so the generator is not called forever
+	items, err := Collect(context.Background(), Take(NewGeneratorStream(context.Background(), nopMapper)(generator), 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 5, len(items); want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+}
+
+func TestTakeClose(t *testing.T) {
+
+	all := func(context.Context, int) (bool, error) { return true, nil }
+
+	closeWhileBlocked(t, Take(blockingInts(), 5))
+	closeWhileBlocked(t, TakeWhile(context.Background(), blockingInts(), all))
+	closeWhileBlocked(t, Skip(blockingInts(), 5))
+}
+
+func TestSkip(t *testing.T) {
+
+	ctx := context.Background()
+
+	items, err := Collect(ctx, Skip(FromSlice(ints), 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := []int{7, 8, 9}, items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+
+	odd := func(_ context.Context, item int) (bool, error) { return item%2 == 1, nil }
+
+	items, err = Collect(ctx, SkipWhile(ctx, FromSlice(ints), odd))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := []int{2, 3, 4, 5, 6, 7, 8, 9}, items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,240 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSlowConsumer is returned by a branch of a Tee with the TeeFail policy, which could not keep up.
+var ErrSlowConsumer = errors.New("slow consumer")
+
+// TeePolicy is the policy of a Tee for branches whose buffer is full.
+type TeePolicy int
+
+const (
+	// TeeBlock is blocking all branches until the slow branch is ready to receive the next item.
+	TeeBlock TeePolicy = iota
+	// TeeDrop is dropping the item for the slow branch.
+	TeeDrop
+	// TeeFail is failing the slow branch, which is returning ErrSlowConsumer after its buffered items.
+	TeeFail
+)
+
+type teeConf struct {
+	BufSize int
+	Policy  TeePolicy
+}
+
+// newTeeConf is creating a default tee config and applies the given TeeOpts.
+func newTeeConf(opts ...TeeOpt) *teeConf {
+	conf := &teeConf{
+		BufSize: 0,
+		Policy:  TeeBlock,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// TeeOpt is a functional option type for Tee.
+type TeeOpt func(conf *teeConf)
+
+// TeeBufSizeOpt is a functional option setting the channel buffer size of every branch (default: 0).
+func TeeBufSizeOpt(size int) TeeOpt {
+	return func(conf *teeConf) {
+		conf.BufSize = size
+	}
+}
+
+// TeePolicyOpt is a functional option setting the policy for branches whose buffer is full (default: TeeBlock).
+func TeePolicyOpt(policy TeePolicy) TeeOpt {
+	return func(conf *teeConf) {
+		conf.Policy = policy
+	}
+}
+
+// Tee is returning n iterators which are all returning the items and errors of the given iterator.
+// Errors are delivered to every branch regardless of the TeePolicy. The given iterator is closed after
+// it was exhausted or all branches were closed.
+func Tee[T any](it TypedIterator[T], n int, opts ...TeeOpt) []TypedIterator[T] {
+	if n < 1 {
+		panic(fmt.Sprintf("nr of tee branches: %d - need at least 1 branch", n))
+	}
+
+	cfg := newTeeConf(opts...)
+
+	t := &tee[T]{cfg: cfg, upstream: it, open: n}
+
+	branches := make([]*teeBranch[T], n)
+	iters := make([]TypedIterator[T], n)
+
+	for i := range branches {
+		branches[i] = &teeBranch[T]{
+			tee:    t,
+			ch:     make(chan result[T], cfg.BufSize),
+			closed: make(chan struct{}),
+		}
+		iters[i] = branches[i]
+	}
+
+	go t.distribute(branches)
+
+	return iters
+}
+
+// tee is holding the state shared by the branches of a Tee.
+type tee[T any] struct {
+	cfg      *teeConf
+	upstream TypedIterator[T]
+
+	mu   sync.Mutex
+	open int
+
+	closeOnce sync.Once
+}
+
+// closeUpstream is closing the upstream iterator once.
+func (t *tee[T]) closeUpstream() {
+	t.closeOnce.Do(t.upstream.Close)
+}
+
+// branchClosed is closing the upstream iterator after the last branch was closed, which is unblocking
+// the distributor waiting for the next upstream item.
+func (t *tee[T]) branchClosed() {
+	t.mu.Lock()
+	t.open--
+	last := t.open == 0
+	t.mu.Unlock()
+
+	if last {
+		t.closeUpstream()
+	}
+}
+
+// distribute is delivering the upstream items to all branches until the upstream is exhausted
+// or all branches are detached.
+func (t *tee[T]) distribute(branches []*teeBranch[T]) {
+	defer t.closeUpstream()
+
+	attached := len(branches)
+
+	defer func() {
+		for _, b := range branches {
+			if !b.detached {
+				close(b.ch)
+			}
+		}
+	}()
+
+	for attached > 0 {
+		item, err := t.upstream.Next()
+		if err == io.EOF {
+			return
+		}
+
+		res := result[T]{item: item, err: err}
+
+		for _, b := range branches {
+			if b.detached {
+				continue
+			}
+
+			if !b.deliver(res) {
+				b.detach()
+				attached--
+			}
+		}
+	}
+}
+
+// teeBranch is implementing the TypedIterator interface for a branch of a Tee.
+type teeBranch[T any] struct {
+	tee *tee[T]
+	ch  chan result[T]
+
+	// closed is closed by Close, letting the distributor detach the branch.
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// detached and err are only written by the distributor before closing ch.
+	detached bool
+	err      error
+	reported atomic.Bool
+}
+
+// deliver is delivering the given result according to the TeePolicy. It returns false if the branch
+// was closed or failed and needs to be detached.
+func (b *teeBranch[T]) deliver(res result[T]) bool {
+
+	select {
+	case <-b.closed:
+		return false
+	default:
+	}
+
+	if res.err != nil || b.tee.cfg.Policy == TeeBlock {
+		select {
+		case b.ch <- res:
+			return true
+		case <-b.closed:
+			return false
+		}
+	}
+
+	select {
+	case b.ch <- res:
+		return true
+	default:
+	}
+
+	if b.tee.cfg.Policy == TeeFail {
+		b.err = ErrSlowConsumer
+		return false
+	}
+
+	// TeeDrop
+	return true
+}
+
+// detach is closing the channel of the branch, so it is not receiving any more items.
+func (b *teeBranch[T]) detach() {
+	b.detached = true
+	close(b.ch)
+}
+
+// Next is returning the next item of the branch or io.EOF when the upstream is exhausted or the branch was closed.
+func (b *teeBranch[T]) Next() (T, error) {
+	var zero T
+
+	select {
+	case <-b.closed:
+		return zero, io.EOF
+	default:
+	}
+
+	select {
+	case res, ok := <-b.ch:
+		if !ok {
+			if b.err != nil && b.reported.CompareAndSwap(false, true) {
+				return zero, b.err
+			}
+			return zero, io.EOF
+		}
+		return res.item, res.err
+
+	case <-b.closed:
+		return zero, io.EOF
+	}
+}
+
+// Close is detaching the branch from the Tee. The upstream iterator is closed after the last branch was closed.
+func (b *teeBranch[T]) Close() {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+		b.tee.branchClosed()
+	})
+}
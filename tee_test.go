@@ -0,0 +1,170 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTee(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		stream := NewStream(context.Background(), failingMapper,
+			BufSizeOpt(parms.bufSize), WorkersOpt(parms.workers), OrderedOpt(true), ContOnErrOpt(true))
+
+		branches := Tee(stream(&testIter{list: list}), 3, TeeBufSizeOpt(parms.bufSize))
+
+		wg := sync.WaitGroup{}
+
+		for _, branch := range branches {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer branch.Close()
+
+				for i := 0; i < len(list); i++ {
+					a, err := branch.Next()
+					if i == 4 {
+						if !errors.Is(err, errFive) {
+							t.Errorf("test %d: Expected errFive, got %v", testnr, err)
+						}
+						continue
+					}
+					if err != nil {
+						t.Errorf("test %d: %v", testnr, err)
+						return
+					}
+					if want, got := list[i].input, a.(data).input; want != got {
+						t.Errorf("test %d: Expected item %d, got %d", testnr, want, got)
+					}
+				}
+
+				if _, err := branch.Next(); err != io.EOF {
+					t.Errorf("test %d: Expected io.EOF: %v", testnr, err)
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+}
+
+// slowInts is returning an iterator over ints pausing before every item, so a fast consumer is keeping up.
+func slowInts() TypedIterator[int] {
+	slow := func(_ context.Context, item int) (int, error) {
+		time.Sleep(time.Millisecond)
+		return item, nil
+	}
+	return NewStream(context.Background(), slow)(FromSlice(ints))
+}
+
+func TestTeeDrop(t *testing.T) {
+
+	branches := Tee(slowInts(), 2, TeePolicyOpt(TeeDrop), TeeBufSizeOpt(2))
+	fast, slow := branches[0], branches[1]
+	defer slow.Close()
+
+	// the slow branch is not read until the fast branch is exhausted
+	n := 0
+	for {
+		if _, err := fast.Next(); err == io.EOF {
+			break
+		}
+		n++
+	}
+	fast.Close()
+
+	if want, got := len(ints), n; want != got {
+		t.Fatalf("Expected %d items in the fast branch, got %d", want, got)
+	}
+
+	items := []int{}
+	for {
+		item, err := slow.Next()
+		if err == io.EOF {
+			break
+		}
+		items = append(items, item)
+	}
+
+	if want, got := []int{1, 2}, items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v in the slow branch, got %v", want, got)
+	}
+}
+
+func TestTeeFail(t *testing.T) {
+
+	branches := Tee(slowInts(), 2, TeePolicyOpt(TeeFail), TeeBufSizeOpt(2))
+	fast, slow := branches[0], branches[1]
+	defer fast.Close()
+	defer slow.Close()
+
+	for {
+		_, err := fast.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, want := range []int{1, 2} {
+		got, err := slow.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != got {
+			t.Fatalf("Expected item %d, got %d", want, got)
+		}
+	}
+
+	if _, err := slow.Next(); err != ErrSlowConsumer {
+		t.Fatalf("Expected ErrSlowConsumer, got %v", err)
+	}
+
+	if _, err := slow.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+}
+
+func TestTeeClose(t *testing.T) {
+
+	generator := func() (int, error) {
+		return 1, nil
+	}
+
+	nop := func(_ context.Context, item int) (int, error) { return item, nil }
+
+	upstream := &closeRecorder{TypedIterator: NewGeneratorStream(context.Background(), nop)(generator)}
+
+	branches := Tee[int](upstream, 2)
+
+	if _, err := branches[0].Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	branches[0].Close()
+	branches[0].Close()
+
+	// the upstream is kept open for the other branch
+	time.Sleep(time.Millisecond)
+	if upstream.closed {
+		t.Fatal("Expected upstream not to be closed before all branches are closed")
+	}
+
+	if _, err := branches[1].Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	branches[1].Close()
+
+	if !upstream.closed {
+		t.Fatal("Expected upstream to be closed after all branches are closed")
+	}
+}
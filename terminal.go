@@ -0,0 +1,191 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrEmpty is returned by First and Reduce for an iterator without items.
+var ErrEmpty = errors.New("empty iterator")
+
+// forEach is calling fn for the items of the given iterator until it is exhausted, fn returns false or an error,
+// or the context is done. When the context is done, the iterator is closed for unblocking a pending Next.
+// The iterator is closed in any case.
+func forEach[T any](ctx context.Context, it TypedIterator[T], fn func(item T) (bool, error)) error {
+	// the iterator is closed once, and before returning even if the context is done concurrently
+	var once sync.Once
+	closeIt := func() { once.Do(it.Close) }
+	defer closeIt()
+
+	stop := context.AfterFunc(ctx, closeIt)
+	defer stop()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		item, err := it.Next()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if more, err := fn(item); err != nil || !more {
+			return err
+		}
+	}
+}
+
+// Collect is returning all items of the given iterator. On error, it is returning the items collected so far
+// together with the error. The iterator is closed in any case.
+func Collect[T any](ctx context.Context, it TypedIterator[T]) ([]T, error) {
+	items := []T{}
+
+	err := forEach(ctx, it, func(item T) (bool, error) {
+		items = append(items, item)
+		return true, nil
+	})
+
+	return items, err
+}
+
+// Count is returning the amount of items of the given iterator. The iterator is closed in any case.
+func Count[T any](ctx context.Context, it TypedIterator[T]) (int, error) {
+	count := 0
+
+	err := forEach(ctx, it, func(T) (bool, error) {
+		count++
+		return true, nil
+	})
+
+	return count, err
+}
+
+// Fold is combining the items of the given iterator with the given func, starting with the initial value of
+// the accumulator. The iterator is closed in any case.
+func Fold[T, Acc any](ctx context.Context, it TypedIterator[T], initial Acc, fn func(acc Acc, item T) (Acc, error)) (Acc, error) {
+	acc := initial
+
+	err := forEach(ctx, it, func(item T) (bool, error) {
+		var err error
+		acc, err = fn(acc, item)
+		return true, err
+	})
+
+	return acc, err
+}
+
+// Reduce is like Fold, but starts with the first item as initial value of the accumulator.
+// It is returning ErrEmpty for an iterator without items. The iterator is closed in any case.
+func Reduce[T any](ctx context.Context, it TypedIterator[T], fn func(acc, item T) (T, error)) (T, error) {
+	var acc T
+	first := true
+
+	err := forEach(ctx, it, func(item T) (bool, error) {
+		if first {
+			acc, first = item, false
+			return true, nil
+		}
+
+		var err error
+		acc, err = fn(acc, item)
+		return true, err
+	})
+
+	if err == nil && first {
+		err = ErrEmpty
+	}
+
+	return acc, err
+}
+
+// First is returning the first item of the given iterator, or ErrEmpty for an iterator without items.
+// The iterator is closed afterwards.
+func First[T any](ctx context.Context, it TypedIterator[T]) (T, error) {
+	var first T
+	found := false
+
+	err := forEach(ctx, it, func(item T) (bool, error) {
+		first, found = item, true
+		return false, nil
+	})
+
+	if err == nil && !found {
+		err = ErrEmpty
+	}
+
+	return first, err
+}
+
+// Any is returning true if at least one item of the given iterator is matching the predicate. It stops at the
+// first matching item and closes the iterator.
+func Any[T any](ctx context.Context, it TypedIterator[T], predicate TypedPredicate[T]) (bool, error) {
+	found := false
+
+	err := forEach(ctx, it, func(item T) (bool, error) {
+		match, err := predicate(ctx, item)
+		found = match && err == nil
+		return !found, err
+	})
+
+	return found, err
+}
+
+// All is returning true if all items of the given iterator are matching the predicate. It stops at the
+// first item not matching and closes the iterator.
+func All[T any](ctx context.Context, it TypedIterator[T], predicate TypedPredicate[T]) (bool, error) {
+	all := true
+
+	err := forEach(ctx, it, func(item T) (bool, error) {
+		match, err := predicate(ctx, item)
+		all = match && err == nil
+		return all, err
+	})
+
+	return all, err
+}
+
+// ForEach is calling fn for all items of the given iterator. The StreamOpts are applied to a stream calling fn,
+// e.g. use WorkersOpt for calling fn in parallel. ForEach is returning the first error, or with ContOnErrOpt
+// the joined errors of all items. The iterator is closed in any case.
+func ForEach[T any](ctx context.Context, it TypedIterator[T], fn func(ctx context.Context, item T) error, opts ...StreamOpt) error {
+
+	mapper := func(ctx context.Context, item T) (struct{}, error) {
+		if err := fn(ctx, item); err != nil {
+			return struct{}{}, err
+		}
+		return struct{}{}, ErrSkip
+	}
+
+	// the full slice expression is keeping append from writing into the spare capacity of the caller's slice
+	stream := NewStream(ctx, mapper, append(opts[:len(opts):len(opts)], CloseInputOpt(true))...)(it)
+	defer stream.Close()
+
+	var errs []error
+	for {
+		_, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return ctx.Err()
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
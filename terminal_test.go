@@ -0,0 +1,211 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCollect(t *testing.T) {
+
+	in := &closeRecorder{TypedIterator: FromSlice(ints)}
+
+	items, err := Collect[int](context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := ints, items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+
+	if !in.closed {
+		t.Fatal("Expected input to be closed")
+	}
+
+	// the items before the error are returned together with the error
+	results, err := Collect(context.Background(), NewStream(context.Background(), failingMapper, OrderedOpt(true))(&testIter{list: list}))
+	if !errors.Is(err, errFive) {
+		t.Fatalf("Expected errFive, got %v", err)
+	}
+
+	if want, got := 4, len(results); want != got {
+		t.Fatalf("Expected %d items, got %d", want, got)
+	}
+}
+
+func TestCollectCanceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := &closeRecorder{TypedIterator: FromSlice(ints)}
+
+	if _, err := Collect[int](ctx, in); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if !in.closed {
+		t.Fatal("Expected input to be closed")
+	}
+}
+
+func TestReduceFold(t *testing.T) {
+
+	ctx := context.Background()
+	sum := func(acc, item int) (int, error) { return acc + item, nil }
+
+	if total, err := Reduce(ctx, FromSlice(ints), sum); err != nil || total != 45 {
+		t.Fatalf("Expected 45, got %d: %v", total, err)
+	}
+
+	if _, err := Reduce(ctx, FromSlice([]int{}), sum); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Expected ErrEmpty, got %v", err)
+	}
+
+	joined, err := Fold(ctx, FromSlice(ints), "", func(acc string, item int) (string, error) {
+		if item == 5 {
+			return acc, errFive
+		}
+		return acc + string(rune('0'+item)), nil
+	})
+	if !errors.Is(err, errFive) {
+		t.Fatalf("Expected errFive, got %v", err)
+	}
+
+	if want, got := "1234", joined; want != got {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+
+	if count, err := Count(ctx, FromSlice(ints)); err != nil || count != 9 {
+		t.Fatalf("Expected 9 items, got %d: %v", count, err)
+	}
+}
+
+func TestFirstAnyAll(t *testing.T) {
+
+	ctx := context.Background()
+	in := &closeRecorder{TypedIterator: FromSlice(ints)}
+
+	if first, err := First[int](ctx, in); err != nil || first != 1 {
+		t.Fatalf("Expected 1, got %d: %v", first, err)
+	}
+
+	if !in.closed {
+		t.Fatal("Expected input to be closed")
+	}
+
+	if _, err := First(ctx, FromSlice([]int{})); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Expected ErrEmpty, got %v", err)
+	}
+
+	greaterThan := func(n int) TypedPredicate[int] {
+		return func(_ context.Context, item int) (bool, error) { return item > n, nil }
+	}
+
+	for testnr, parms := range []struct {
+		fn   func(context.Context, TypedIterator[int], TypedPredicate[int]) (bool, error)
+		pred TypedPredicate[int]
+		want bool
+	}{
+		{fn: Any[int], pred: greaterThan(8), want: true},
+		{fn: Any[int], pred: greaterThan(9), want: false},
+		{fn: All[int], pred: greaterThan(0), want: true},
+		{fn: All[int], pred: greaterThan(1), want: false},
+	} {
+		got, err := parms.fn(ctx, FromSlice(ints), parms.pred)
+		if err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+		if parms.want != got {
+			t.Fatalf("test %d: Expected %v, got %v", testnr, parms.want, got)
+		}
+	}
+}
+
+func TestForEach(t *testing.T) {
+
+	for testnr, parms := range testCases {
+
+		var mu sync.Mutex
+		items := []int{}
+
+		err := ForEach(context.Background(), FromSlice(ints), func(_ context.Context, item int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			items = append(items, item)
+			return nil
+		}, WorkersOpt(parms.workers), BufSizeOpt(parms.bufSize))
+		if err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		slices.Sort(items)
+		if want, got := ints, items; !slices.Equal(want, got) {
+			t.Fatalf("test %d: Expected items %v, got %v", testnr, want, got)
+		}
+	}
+
+	failing := func(_ context.Context, item int) error {
+		if item%2 == 0 {
+			return errFive
+		}
+		return nil
+	}
+
+	if err := ForEach(context.Background(), FromSlice(ints), failing, WorkersOpt(3)); !errors.Is(err, errFive) {
+		t.Fatalf("Expected errFive, got %v", err)
+	}
+
+	// with continue-on-error, the errors of all items are joined
+	err := ForEach(context.Background(), FromSlice(ints), failing, WorkersOpt(3), ContOnErrOpt(true))
+
+	var streamErr *StreamError
+	if !errors.As(err, &streamErr) {
+		t.Fatalf("Expected a StreamError, got %v", err)
+	}
+
+	if want, got := 4, len(err.(interface{ Unwrap() []error }).Unwrap()); want != got {
+		t.Fatalf("Expected %d errors, got %d", want, got)
+	}
+}
+
+func TestForEachOpts(t *testing.T) {
+
+	// the caller's slice of options must not be modified
+	opts := make([]StreamOpt, 1, 2)
+	opts[0] = WorkersOpt(2)
+
+	if err := ForEach(context.Background(), FromSlice(ints), func(context.Context, int) error { return nil }, opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts[:2][1] != nil {
+		t.Fatalf("Expected the spare capacity of the options to be untouched")
+	}
+}
+
+func TestCollectBlockedCanceled(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// the canceled context is unblocking the pending Next of the input
+	done := make(chan error)
+	go func() {
+		_, err := Collect(ctx, blockingInts())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Collect to return after the context is done")
+	}
+}
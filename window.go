@@ -0,0 +1,528 @@
+package iter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Window is a group of items emitted by the windowing stages. Start and End are depending on the kind of window,
+// see CountWindows, TimeWindows and SessionWindows.
+type Window[T any] struct {
+	Start time.Time
+	End   time.Time
+	Items []T
+}
+
+// Clock is providing the current time and timers to the windowing stages, so the time can be controlled in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock using the wall-clock time.
+type realClock struct{}
+
+// Now is returning the current time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After is returning a channel receiving the time after the given duration.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type windowConf struct {
+	Clock    Clock
+	Lateness time.Duration
+	Late     func(item interface{})
+}
+
+// newWindowConf is creating a default window config and applies the given WindowOpts.
+func newWindowConf(opts ...WindowOpt) *windowConf {
+	conf := &windowConf{
+		Clock:    realClock{},
+		Lateness: 0,
+		Late:     nil,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// WindowOpt is a functional option type for the windowing stages.
+type WindowOpt func(conf *windowConf)
+
+// WindowClockOpt is a functional option setting the Clock used for timing the windows (default: wall-clock time).
+func WindowClockOpt(clock Clock) WindowOpt {
+	return func(conf *windowConf) {
+		conf.Clock = clock
+	}
+}
+
+// WindowLatenessOpt is a functional option setting the allowed lateness of items in EventTimeWindows,
+// which is the time the watermark is lagging behind the latest event time seen (default: 0).
+func WindowLatenessOpt(d time.Duration) WindowOpt {
+	return func(conf *windowConf) {
+		conf.Lateness = d
+	}
+}
+
+// WindowLateItemsOpt is a functional option setting a func receiving the items arriving too late for
+// EventTimeWindows, after all their windows were emitted (default: nil - late items are dropped).
+// The func is called by the goroutine assigning the items to windows and should not block.
+func WindowLateItemsOpt[T any](late func(item T)) WindowOpt {
+	return func(conf *windowConf) {
+		conf.Late = func(item interface{}) {
+			late(item.(T))
+		}
+	}
+}
+
+// windower is assigning timestamped items to windows.
+type windower[T any] interface {
+	// add is adding an item arrived at the given time, returning the windows completed by it.
+	add(at time.Time, item T) []Window[T]
+	// expire is returning the windows completed at the given time.
+	expire(now time.Time) []Window[T]
+	// deadline is returning the time when the next window is completed by time, if any.
+	deadline() (time.Time, bool)
+	// flush is returning the pending windows after the input is exhausted.
+	flush() []Window[T]
+}
+
+// CountWindows is returning a TypedIterator grouping the items of the given iterator into windows of
+// size items, starting a new window every slide items. With slide equal to size the windows are tumbling,
+// with a smaller slide they are sliding and overlapping. Start and End of a window are the arrival times of
+// its first and last item. When the input is exhausted, a partially filled window is emitted if it contains
+// items not emitted in any other window. Errors of the input are passed through. The input iterator is closed
+// when the returned iterator is closed or finished.
+func CountWindows[T any](ctx context.Context, it TypedIterator[T], size, slide int, opts ...WindowOpt) TypedIterator[Window[T]] {
+	if size < 1 || slide < 1 {
+		panic(fmt.Sprintf("count window size: %d, slide: %d - need at least 1 item", size, slide))
+	}
+
+	return runWindows[T](ctx, it, &countWindower[T]{size: size, slide: slide, covered: -1}, newWindowConf(opts...))
+}
+
+// TimeWindows is returning a TypedIterator grouping the items of the given iterator into windows by their
+// arrival time. The windows have a duration of size and a new window is starting every slide. With slide equal
+// to size the windows are tumbling, with a smaller slide they are sliding and overlapping. The windows are
+// aligned to multiples of slide since the zero time and emitted as soon as their End has passed, empty
+// windows are skipped. Pending windows are emitted when the input is exhausted. Errors of the input are
+// passed through. The input iterator is closed when the returned iterator is closed or finished.
+func TimeWindows[T any](ctx context.Context, it TypedIterator[T], size, slide time.Duration, opts ...WindowOpt) TypedIterator[Window[T]] {
+	if size <= 0 || slide <= 0 {
+		panic(fmt.Sprintf("time window size: %v, slide: %v - need a positive duration", size, slide))
+	}
+
+	return runWindows[T](ctx, it, &timeWindower[T]{size: size, slide: slide}, newWindowConf(opts...))
+}
+
+// SessionWindows is returning a TypedIterator grouping the items of the given iterator into sessions, which
+// are emitted when no item arrived for the duration of gap. Start of a session is the arrival time of its first
+// item, End is the arrival time of its last item plus the gap. A pending session is emitted when the input
+// is exhausted. Errors of the input are passed through. The input iterator is closed when the returned iterator
+// is closed or finished.
+func SessionWindows[T any](ctx context.Context, it TypedIterator[T], gap time.Duration, opts ...WindowOpt) TypedIterator[Window[T]] {
+	if gap <= 0 {
+		panic(fmt.Sprintf("session gap: %v - need a positive duration", gap))
+	}
+
+	return runWindows[T](ctx, it, &sessionWindower[T]{gap: gap}, newWindowConf(opts...))
+}
+
+// EventTimeWindows is returning a TypedIterator grouping the items of the given iterator into windows by their
+// event time, as returned by the given timestamp func. The windows have a duration of size and a new window
+// is starting every slide, aligned like in TimeWindows. The items may arrive out of order: the watermark is
+// following the latest event time seen, lagging behind by the allowed lateness (see WindowLatenessOpt), and
+// a window is emitted when the watermark passes its End. Items arriving after all their windows were emitted
+// are passed to the func set with WindowLateItemsOpt, while items in the gap between windows with a slide
+// larger than the size are skipped. Pending windows are emitted when the input is exhausted.
+// Errors of the input are passed through. The input iterator is closed when the returned iterator is closed
+// or finished.
+func EventTimeWindows[T any](ctx context.Context, it TypedIterator[T], timestamp func(item T) time.Time, size, slide time.Duration, opts ...WindowOpt) TypedIterator[Window[T]] {
+	if size <= 0 || slide <= 0 {
+		panic(fmt.Sprintf("event time window size: %v, slide: %v - need a positive duration", size, slide))
+	}
+
+	cfg := newWindowConf(opts...)
+
+	w := &eventTimeWindower[T]{
+		timestamp: timestamp,
+		size:      size,
+		slide:     slide,
+		lateness:  cfg.Lateness,
+		late:      cfg.Late,
+	}
+
+	return runWindows[T](ctx, it, w, cfg)
+}
+
+// timedResult is a result of an input iterator with its arrival time.
+type timedResult[T any] struct {
+	result[T]
+	at time.Time
+}
+
+// runWindows is starting the goroutines assigning the items of the given iterator to windows with the given windower.
+func runWindows[T any](ctx context.Context, it TypedIterator[T], w windower[T], cfg *windowConf) TypedIterator[Window[T]] {
+
+	windowChan := make(chan Window[T])
+	errChan := make(chan error)
+	done := make(chan struct{})
+
+	myCtx, cancel := context.WithCancelCause(ctx)
+
+	iter := newStreamIterator(windowChan, errChan, func() { cancel(ErrClosed) }, done)
+
+	// the reader goroutine is taking the arrival time right after pulling an item, so it is
+	// not depending on how fast the windows are emitted
+	items := readAll(myCtx, it, func(item T, err error) timedResult[T] {
+		return timedResult[T]{result: newResult(item, err), at: cfg.Clock.Now()}
+	})
+
+	go func() {
+		defer close(done)
+		defer close(windowChan)
+
+		// closing the input is unblocking the reader, which is exiting before the input is released
+		defer func() {
+			it.Close()
+			for range items {
+			}
+		}()
+
+		if completed := window(myCtx, cfg.Clock, w, items, windowChan, errChan); completed {
+			iter.finish(StateCompleted, nil)
+		} else {
+			iter.finish(StateCanceled, context.Cause(myCtx))
+		}
+	}()
+
+	return iter
+}
+
+// window is assigning the items to windows until the items channel is closed (returning true)
+// or the context is done (returning false).
+func window[T any](ctx context.Context, clock Clock, w windower[T], items <-chan timedResult[T], windowChan chan<- Window[T], errChan chan<- error) bool {
+
+	emit := func(windows []Window[T]) bool {
+		for _, win := range windows {
+			select {
+			case windowChan <- win:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+
+	for {
+		var timer <-chan time.Time
+		if deadline, ok := w.deadline(); ok {
+			timer = clock.After(deadline.Sub(clock.Now()))
+		}
+
+		select {
+		case res, ok := <-items:
+			if !ok {
+				return emit(w.flush())
+			}
+
+			if res.err != nil {
+				select {
+				case errChan <- res.err:
+				case <-ctx.Done():
+					return false
+				}
+				continue
+			}
+
+			// the windows which are already due are emitted first, so the result is not depending
+			// on whether the item or the timer is arriving first
+			if !emit(w.expire(res.at)) || !emit(w.add(res.at, res.item)) {
+				return false
+			}
+
+		case now := <-timer:
+			if !emit(w.expire(now)) {
+				return false
+			}
+
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// countWindower is implementing the windower for CountWindows.
+type countWindower[T any] struct {
+	size  int
+	slide int
+
+	// open are the windows which are not full yet, the oldest first
+	open []Window[T]
+	// count is the amount of items added
+	count int
+	// covered is the index of the last item in an emitted window
+	covered int
+}
+
+// add is appending the item to all open windows and returns the full ones, opening a new window every slide items.
+func (w *countWindower[T]) add(at time.Time, item T) []Window[T] {
+	if w.count%w.slide == 0 {
+		w.open = append(w.open, Window[T]{Start: at})
+	}
+	w.count++
+
+	var full []Window[T]
+	for i := range w.open {
+		w.open[i].Items = append(w.open[i].Items, item)
+		w.open[i].End = at
+	}
+
+	for len(w.open) > 0 && len(w.open[0].Items) >= w.size {
+		full = append(full, w.open[0])
+		w.open = w.open[1:]
+		w.covered = w.count - 1
+	}
+
+	return full
+}
+
+// expire is a no-op, as count windows are not completed by time.
+func (w *countWindower[T]) expire(time.Time) []Window[T] {
+	return nil
+}
+
+// deadline is never set for count windows.
+func (w *countWindower[T]) deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// flush is returning the oldest open window, if it contains items which were not emitted yet.
+func (w *countWindower[T]) flush() []Window[T] {
+	if len(w.open) == 0 || w.count-1 <= w.covered {
+		return nil
+	}
+	return w.open[:1]
+}
+
+// timeWindower is implementing the windower for TimeWindows.
+type timeWindower[T any] struct {
+	size  time.Duration
+	slide time.Duration
+
+	// open are the windows with items which are not completed yet, ordered by their start
+	open []Window[T]
+}
+
+// add is appending the item to all windows containing its arrival time, opening them as needed.
+func (w *timeWindower[T]) add(at time.Time, item T) []Window[T] {
+
+	// the starts of all windows containing the item, the latest first
+	var starts []time.Time
+	for start := at.Truncate(w.slide); start.Add(w.size).After(at); start = start.Add(-w.slide) {
+		starts = append(starts, start)
+	}
+
+	for i := len(starts) - 1; i >= 0; i-- {
+		start := starts[i]
+
+		found := false
+		for j := range w.open {
+			if w.open[j].Start.Equal(start) {
+				w.open[j].Items = append(w.open[j].Items, item)
+				found = true
+				break
+			}
+		}
+
+		// windows are opened in order of their start, as the arrival times are increasing
+		if !found {
+			w.open = append(w.open, Window[T]{Start: start, End: start.Add(w.size), Items: []T{item}})
+		}
+	}
+
+	return nil
+}
+
+// expire is returning the windows ending before or at the given time.
+func (w *timeWindower[T]) expire(now time.Time) []Window[T] {
+	var due []Window[T]
+	for len(w.open) > 0 && !w.open[0].End.After(now) {
+		due = append(due, w.open[0])
+		w.open = w.open[1:]
+	}
+	return due
+}
+
+// deadline is returning the end of the oldest open window.
+func (w *timeWindower[T]) deadline() (time.Time, bool) {
+	if len(w.open) == 0 {
+		return time.Time{}, false
+	}
+	return w.open[0].End, true
+}
+
+// flush is returning all open windows.
+func (w *timeWindower[T]) flush() []Window[T] {
+	return w.open
+}
+
+// sessionWindower is implementing the windower for SessionWindows.
+type sessionWindower[T any] struct {
+	gap     time.Duration
+	session *Window[T]
+}
+
+// add is appending the item to the current session, extending it by the gap.
+func (w *sessionWindower[T]) add(at time.Time, item T) []Window[T] {
+	if w.session == nil {
+		w.session = &Window[T]{Start: at}
+	}
+	w.session.Items = append(w.session.Items, item)
+	w.session.End = at.Add(w.gap)
+
+	return nil
+}
+
+// expire is returning the current session if no item arrived within the gap.
+func (w *sessionWindower[T]) expire(now time.Time) []Window[T] {
+	if w.session == nil || w.session.End.After(now) {
+		return nil
+	}
+	return w.flush()
+}
+
+// deadline is returning the end of the current session.
+func (w *sessionWindower[T]) deadline() (time.Time, bool) {
+	if w.session == nil {
+		return time.Time{}, false
+	}
+	return w.session.End, true
+}
+
+// flush is returning the current session and resets it.
+func (w *sessionWindower[T]) flush() []Window[T] {
+	if w.session == nil {
+		return nil
+	}
+	session := *w.session
+	w.session = nil
+	return []Window[T]{session}
+}
+
+// eventTimeWindower is implementing the windower for EventTimeWindows.
+type eventTimeWindower[T any] struct {
+	timestamp func(item T) time.Time
+	size      time.Duration
+	slide     time.Duration
+	lateness  time.Duration
+	late      func(item interface{})
+
+	// open are the windows with items which were not emitted yet, ordered by their start
+	open      []Window[T]
+	watermark time.Time
+}
+
+// add is appending the item to all windows containing its event time which were not emitted yet, and
+// returns the windows completed by the advanced watermark. An item whose windows were all emitted already
+// is late, while an item in the gap between hopping windows is skipped.
+func (w *eventTimeWindower[T]) add(_ time.Time, item T) []Window[T] {
+	ts := w.timestamp(item)
+
+	contained, added := false, false
+	for start := ts.Truncate(w.slide); start.Add(w.size).After(ts); start = start.Add(-w.slide) {
+		contained = true
+		if !start.Add(w.size).After(w.watermark) {
+			break
+		}
+		w.insert(start, item)
+		added = true
+	}
+
+	if contained && !added && w.late != nil {
+		w.late(item)
+	}
+
+	if watermark := ts.Add(-w.lateness); watermark.After(w.watermark) {
+		w.watermark = watermark
+	}
+
+	var due []Window[T]
+	for len(w.open) > 0 && !w.open[0].End.After(w.watermark) {
+		due = append(due, w.open[0])
+		w.open = w.open[1:]
+	}
+	return due
+}
+
+// insert is appending the item to the window with the given start, which is inserted in order if not open yet.
+func (w *eventTimeWindower[T]) insert(start time.Time, item T) {
+	i := 0
+	for ; i < len(w.open) && w.open[i].Start.Before(start); i++ {
+	}
+
+	if i == len(w.open) || !w.open[i].Start.Equal(start) {
+		w.open = slices.Insert(w.open, i, Window[T]{Start: start, End: start.Add(w.size)})
+	}
+	w.open[i].Items = append(w.open[i].Items, item)
+}
+
+// expire is a no-op, as event time windows are only completed by the watermark.
+func (w *eventTimeWindower[T]) expire(time.Time) []Window[T] {
+	return nil
+}
+
+// deadline is never set for event time windows.
+func (w *eventTimeWindower[T]) deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// flush is returning all open windows.
+func (w *eventTimeWindower[T]) flush() []Window[T] {
+	return w.open
+}
+
+// WindowAggregate is the aggregated value of the items of a window.
+type WindowAggregate[A any] struct {
+	Start time.Time
+	End   time.Time
+	Value A
+}
+
+// AggregateWindows is returning a TypedIterator folding the items of every window returned by the given iterator
+// with the given func, starting with the initial value of the accumulator for every window.
+// Errors of the given iterator and of the func are passed through.
+func AggregateWindows[T, A any](it TypedIterator[Window[T]], initial A, fn func(acc A, item T) (A, error)) TypedIterator[WindowAggregate[A]] {
+	return &aggregateIterator[T, A]{TypedIterator: it, initial: initial, fn: fn}
+}
+
+// aggregateIterator is implementing the TypedIterator interface for AggregateWindows.
+type aggregateIterator[T, A any] struct {
+	TypedIterator[Window[T]]
+	initial A
+	fn      func(acc A, item T) (A, error)
+}
+
+// Next is returning the aggregated value of the next window.
+func (i *aggregateIterator[T, A]) Next() (WindowAggregate[A], error) {
+	win, err := i.TypedIterator.Next()
+	if err != nil {
+		return WindowAggregate[A]{}, err
+	}
+
+	acc := i.initial
+	for _, item := range win.Items {
+		if acc, err = i.fn(acc, item); err != nil {
+			return WindowAggregate[A]{}, err
+		}
+	}
+
+	return WindowAggregate[A]{Start: win.Start, End: win.End, Value: acc}, nil
+}
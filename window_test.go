@@ -0,0 +1,334 @@
+package iter
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock which is only advancing when told to.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// set is setting the time of the clock and fires the timers which are due.
+func (c *fakeClock) set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- now
+	}
+	c.waiters = waiters
+}
+
+// waiting is returning the amount of pending timers.
+func (c *fakeClock) waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+// timedInts is returning an iterator setting the clock to the given offset of the items before returning them.
+func timedInts(clock *fakeClock, start time.Time, offsets map[int]time.Duration) TypedIterator[int] {
+	return FromSeq(func(yield func(int) bool) {
+		for _, i := range ints {
+			clock.set(start.Add(offsets[i]))
+			if !yield(i) {
+				return
+			}
+		}
+	})
+}
+
+// collectWindows is returning the items of all windows.
+func collectWindows(t *testing.T, it TypedIterator[Window[int]]) [][]int {
+	windows, err := Collect(context.Background(), it)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := [][]int{}
+	for _, w := range windows {
+		items = append(items, w.Items)
+	}
+	return items
+}
+
+func TestCountWindows(t *testing.T) {
+
+	testCases := []struct {
+		size  int
+		slide int
+		want  [][]int
+	}{
+		{size: 4, slide: 4, want: [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9}}},
+		{size: 3, slide: 3, want: [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}},
+		{size: 4, slide: 2, want: [][]int{{1, 2, 3, 4}, {3, 4, 5, 6}, {5, 6, 7, 8}, {7, 8, 9}}},
+		{size: 5, slide: 1, want: [][]int{{1, 2, 3, 4, 5}, {2, 3, 4, 5, 6}, {3, 4, 5, 6, 7}, {4, 5, 6, 7, 8}, {5, 6, 7, 8, 9}}},
+		{size: 20, slide: 1, want: [][]int{ints}},
+	}
+
+	for testnr, parms := range testCases {
+
+		windows := collectWindows(t, CountWindows(context.Background(), FromSlice(ints), parms.size, parms.slide))
+
+		if want, got := parms.want, windows; !slices.EqualFunc(want, got, slices.Equal) {
+			t.Fatalf("test %d: Expected windows %v, got %v", testnr, want, got)
+		}
+	}
+}
+
+func TestTimeWindows(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	offsets := map[int]time.Duration{
+		1: 0, 2: 10 * time.Second, 3: 50 * time.Second, 4: 70 * time.Second, 5: 80 * time.Second,
+		6: 200 * time.Second, 7: 210 * time.Second, 8: 230 * time.Second, 9: 250 * time.Second,
+	}
+
+	testCases := []struct {
+		size   time.Duration
+		slide  time.Duration
+		want   [][]int
+		starts []time.Duration
+	}{
+		{
+			size: time.Minute, slide: time.Minute,
+			want:   [][]int{{1, 2, 3}, {4, 5}, {6, 7, 8}, {9}},
+			starts: []time.Duration{0, time.Minute, 3 * time.Minute, 4 * time.Minute},
+		},
+		{
+			size: 2 * time.Minute, slide: time.Minute,
+			want:   [][]int{{1, 2, 3}, {1, 2, 3, 4, 5}, {4, 5}, {6, 7, 8}, {6, 7, 8, 9}, {9}},
+			starts: []time.Duration{-time.Minute, 0, time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute},
+		},
+	}
+
+	for testnr, parms := range testCases {
+
+		clock := &fakeClock{now: start}
+		iter := TimeWindows(context.Background(), timedInts(clock, start, offsets), parms.size, parms.slide, WindowClockOpt(clock))
+
+		windows, err := Collect(context.Background(), iter)
+		if err != nil {
+			t.Fatalf("test %d: %v", testnr, err)
+		}
+
+		for i, w := range windows {
+			if i >= len(parms.want) || !slices.Equal(parms.want[i], w.Items) {
+				t.Fatalf("test %d: Expected window %d to be %v, got %v", testnr, i, parms.want, windows)
+			}
+			if want, got := start.Add(parms.starts[i]), w.Start; !want.Equal(got) {
+				t.Fatalf("test %d: Expected window %d to start at %v, got %v", testnr, i, want, got)
+			}
+			if want, got := w.Start.Add(parms.size), w.End; !want.Equal(got) {
+				t.Fatalf("test %d: Expected window %d to end at %v, got %v", testnr, i, want, got)
+			}
+		}
+
+		if want, got := len(parms.want), len(windows); want != got {
+			t.Fatalf("test %d: Expected %d windows, got %d", testnr, want, got)
+		}
+	}
+}
+
+func TestSessionWindows(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	offsets := map[int]time.Duration{
+		1: 0, 2: 10 * time.Second, 3: 50 * time.Second, 4: 70 * time.Second, 5: 80 * time.Second,
+		6: 200 * time.Second, 7: 210 * time.Second, 8: 230 * time.Second, 9: 300 * time.Second,
+	}
+
+	clock := &fakeClock{now: start}
+	iter := SessionWindows(context.Background(), timedInts(clock, start, offsets), 30*time.Second, WindowClockOpt(clock))
+
+	windows, err := Collect(context.Background(), iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := [][]int{}
+	for _, w := range windows {
+		items = append(items, w.Items)
+	}
+
+	if want, got := [][]int{{1, 2}, {3, 4, 5}, {6, 7, 8}, {9}}, items; !slices.EqualFunc(want, got, slices.Equal) {
+		t.Fatalf("Expected windows %v, got %v", want, got)
+	}
+
+	if want, got := start.Add(110*time.Second), windows[1].End; !want.Equal(got) {
+		t.Fatalf("Expected session to end at %v, got %v", want, got)
+	}
+}
+
+func TestTimeWindowsIdle(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	itemChan := make(chan int)
+	errChan := make(chan error)
+	input := New(itemChan, errChan, func() {})
+
+	iter := TimeWindows(context.Background(), input, time.Minute, time.Minute, WindowClockOpt(clock))
+	defer iter.Close()
+
+	// the iterator returned by New is only stopping when its channels are closed
+	defer close(itemChan)
+
+	itemChan <- 1
+
+	// the window is emitted when the clock passes its end, without waiting for more items
+	for clock.waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.set(start.Add(time.Minute))
+
+	w, err := iter.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := []int{1}, w.Items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+}
+
+func TestAggregateWindows(t *testing.T) {
+
+	sum := func(acc, item int) (int, error) { return acc + item, nil }
+
+	sums, err := Collect(context.Background(), AggregateWindows(CountWindows(context.Background(), FromSlice(ints), 3, 3), 0, sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []int{}
+	for _, s := range sums {
+		values = append(values, s.Value)
+	}
+
+	if want, got := []int{6, 15, 24}, values; !slices.Equal(want, got) {
+		t.Fatalf("Expected sums %v, got %v", want, got)
+	}
+}
+
+func TestEventTimeWindows(t *testing.T) {
+
+	type event struct {
+		name string
+		ts   time.Duration
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []event{{"a", 10 * time.Second}, {"b", 70 * time.Second}, {"c", 20 * time.Second}, {"d", 95 * time.Second},
+		{"e", 50 * time.Second}, {"f", 130 * time.Second}, {"g", 65 * time.Second}}
+
+	late := []string{}
+	iter := EventTimeWindows(context.Background(), FromSlice(events), func(e event) time.Time { return start.Add(e.ts) },
+		time.Minute, time.Minute, WindowLatenessOpt(30*time.Second), WindowLateItemsOpt(func(e event) { late = append(late, e.name) }))
+
+	windows, err := Collect(context.Background(), iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := [][]string{}
+	for _, w := range windows {
+		window := []string{}
+		for _, e := range w.Items {
+			window = append(window, e.name)
+		}
+		names = append(names, window)
+	}
+
+	// the watermark is passing the first window with d, so e is late, while g is still in time
+	if want, got := [][]string{{"a", "c"}, {"b", "d", "g"}, {"f"}}, names; !slices.EqualFunc(want, got, slices.Equal) {
+		t.Fatalf("Expected windows %v, got %v", want, got)
+	}
+
+	if want, got := []string{"e"}, late; !slices.Equal(want, got) {
+		t.Fatalf("Expected late items %v, got %v", want, got)
+	}
+
+	if want, got := start.Add(time.Minute), windows[1].Start; !want.Equal(got) {
+		t.Fatalf("Expected window to start at %v, got %v", want, got)
+	}
+}
+
+func TestEventTimeHoppingWindows(t *testing.T) {
+
+	type event struct {
+		name string
+		ts   time.Duration
+	}
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []event{{"a", 10 * time.Second}, {"b", 40 * time.Second}, {"c", 70 * time.Second}, {"d", 100 * time.Second},
+		{"e", 20 * time.Second}, {"f", 130 * time.Second}}
+
+	late := []string{}
+	iter := EventTimeWindows(context.Background(), FromSlice(events), func(e event) time.Time { return start.Add(e.ts) },
+		30*time.Second, time.Minute, WindowLateItemsOpt(func(e event) { late = append(late, e.name) }))
+
+	windows, err := Collect(context.Background(), iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := [][]string{}
+	for _, w := range windows {
+		window := []string{}
+		for _, e := range w.Items {
+			window = append(window, e.name)
+		}
+		names = append(names, window)
+	}
+
+	// b and d are in the gaps between the windows, so only e is late
+	if want, got := [][]string{{"a"}, {"c"}, {"f"}}, names; !slices.EqualFunc(want, got, slices.Equal) {
+		t.Fatalf("Expected windows %v, got %v", want, got)
+	}
+
+	if want, got := []string{"e"}, late; !slices.Equal(want, got) {
+		t.Fatalf("Expected late items %v, got %v", want, got)
+	}
+}
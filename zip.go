@@ -0,0 +1,408 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Zip is returning a TypedIterator combining the items of the given iterators pairwise with the given func.
+// It is returning io.EOF as soon as one of the iterators is exhausted and closes both iterators then.
+// Errors of the iterators and of the combine func are passed through, without losing the item which was
+// already pulled from the other iterator.
+func Zip[A, B, Out any](a TypedIterator[A], b TypedIterator[B], combine func(a A, b B) (Out, error)) TypedIterator[Out] {
+	return &zipIterator[A, B, Out]{a: a, b: b, combine: combine}
+}
+
+// zipIterator is implementing the TypedIterator interface for Zip.
+type zipIterator[A, B, Out any] struct {
+	// mu is serializing the calls of Next, it is not held by Close.
+	mu      sync.Mutex
+	a       TypedIterator[A]
+	b       TypedIterator[B]
+	combine func(a A, b B) (Out, error)
+
+	// pending is the item of a, if it was pulled but b failed.
+	pending *A
+
+	closed    atomic.Bool
+	closeOnce sync.Once
+}
+
+// Next is returning the combination of the next items of both iterators.
+func (z *zipIterator[A, B, Out]) Next() (Out, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	var zero Out
+
+	if z.closed.Load() {
+		return zero, io.EOF
+	}
+
+	if z.pending == nil {
+		a, err := z.a.Next()
+		if err == io.EOF {
+			z.Close()
+			return zero, io.EOF
+		}
+		if err != nil {
+			return zero, err
+		}
+		z.pending = &a
+	}
+
+	b, err := z.b.Next()
+	if err == io.EOF {
+		z.Close()
+		return zero, io.EOF
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	a := *z.pending
+	z.pending = nil
+
+	return z.combine(a, b)
+}
+
+// Close is closing both iterators, which is unblocking a pending call of Next.
+func (z *zipIterator[A, B, Out]) Close() {
+	z.closeOnce.Do(func() {
+		z.closed.Store(true)
+		z.a.Close()
+		z.b.Close()
+	})
+}
+
+// ErrJoinBufferFull is returned by a Join when the amount of buffered unmatched items exceeds the limit.
+var ErrJoinBufferFull = errors.New("join buffer full")
+
+// JoinMode is the mode of a Join, deciding which unmatched items are emitted.
+type JoinMode int
+
+const (
+	// JoinInner is only emitting matched pairs.
+	JoinInner JoinMode = iota
+	// JoinLeft is also emitting the unmatched items of the left iterator.
+	JoinLeft
+	// JoinOuter is also emitting the unmatched items of both iterators.
+	JoinOuter
+)
+
+// JoinPair is a pair of items emitted by a Join. For unmatched items of a left or outer join,
+// the missing side is the zero value and marked as not ok.
+type JoinPair[A, B any] struct {
+	Left    A
+	Right   B
+	LeftOK  bool
+	RightOK bool
+}
+
+type joinConf struct {
+	Mode      JoinMode
+	MaxBuffer int
+}
+
+// newJoinConf is creating a default join config and applies the given JoinOpts.
+func newJoinConf(opts ...JoinOpt) *joinConf {
+	conf := &joinConf{
+		Mode:      JoinInner,
+		MaxBuffer: 1000,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return conf
+}
+
+// JoinOpt is a functional option type for Join.
+type JoinOpt func(conf *joinConf)
+
+// JoinModeOpt is a functional option setting the mode of a Join (default: JoinInner).
+func JoinModeOpt(mode JoinMode) JoinOpt {
+	return func(conf *joinConf) {
+		conf.Mode = mode
+	}
+}
+
+// JoinBufferOpt is a functional option setting the max amount of unmatched items buffered by a Join (default: 1000).
+func JoinBufferOpt(size int) JoinOpt {
+	if size < 1 {
+		panic(fmt.Sprintf("join buffer size: %d - need a buffer of at least 1 item", size))
+	}
+	return func(conf *joinConf) {
+		conf.MaxBuffer = size
+	}
+}
+
+// Join is returning a TypedIterator emitting the pairs of items of the given iterators with the same key,
+// as returned by the given key funcs. Every item is matched with at most one item of the other iterator,
+// in the order they arrive. Unmatched items are buffered and emitted according to the JoinMode after both
+// iterators are exhausted. The join is failing with ErrJoinBufferFull when exceeding the buffer limit.
+// Errors of the iterators are passed through. Both iterators are closed when the join is closed or finished.
+func Join[A, B any, K comparable](ctx context.Context, a TypedIterator[A], b TypedIterator[B], keyA func(A) K, keyB func(B) K, opts ...JoinOpt) TypedIterator[JoinPair[A, B]] {
+
+	cfg := newJoinConf(opts...)
+
+	itemChan := make(chan JoinPair[A, B])
+	errChan := make(chan error)
+	done := make(chan struct{})
+
+	myCtx, cancel := context.WithCancelCause(ctx)
+
+	iter := newStreamIterator(itemChan, errChan, func() { cancel(ErrClosed) }, done)
+
+	chanA := readAll(myCtx, a, newResult[A])
+	chanB := readAll(myCtx, b, newResult[B])
+
+	go func() {
+		defer close(done)
+		defer close(itemChan)
+
+		// closing the inputs is unblocking the readers
+		defer func() {
+			cancel(nil)
+			a.Close()
+			b.Close()
+			for range chanA {
+			}
+			for range chanB {
+			}
+		}()
+
+		j := &joiner[A, B, K]{
+			cfg:      cfg,
+			keyA:     keyA,
+			keyB:     keyB,
+			pendingA: newPending[K, A](),
+			pendingB: newPending[K, B](),
+			itemChan: itemChan,
+			errChan:  errChan,
+		}
+
+		if err := j.run(myCtx, chanA, chanB); err != nil {
+			iter.finish(StateFailed, err)
+		} else if myCtx.Err() != nil {
+			iter.finish(StateCanceled, context.Cause(myCtx))
+		} else {
+			iter.finish(StateCompleted, nil)
+		}
+	}()
+
+	return iter
+}
+
+// pending is buffering the unmatched items of one side of a Join by key, in arrival order.
+type pending[K comparable, T any] struct {
+	items map[K]*pendingKey[T]
+	// keys is the arrival order of the keys. Keys of popped items are left stale until the next compaction.
+	keys []arrivedKey[K]
+	seq  uint64
+}
+
+// pendingKey is the buffered items of a key, with the sequence number of its arrival.
+type pendingKey[T any] struct {
+	seq   uint64
+	items []T
+}
+
+// arrivedKey is a key in the arrival order of a pending buffer.
+type arrivedKey[K comparable] struct {
+	key K
+	seq uint64
+}
+
+// newPending is returning a new *pending instance.
+func newPending[K comparable, T any]() *pending[K, T] {
+	return &pending[K, T]{items: map[K]*pendingKey[T]{}}
+}
+
+// push is buffering an unmatched item.
+func (p *pending[K, T]) push(key K, item T) {
+	pk, ok := p.items[key]
+	if !ok {
+		p.compact()
+		p.seq++
+		pk = &pendingKey[T]{seq: p.seq}
+		p.items[key] = pk
+		p.keys = append(p.keys, arrivedKey[K]{key: key, seq: p.seq})
+	}
+	pk.items = append(pk.items, item)
+}
+
+// pop is returning the oldest buffered item with the given key, if any.
+func (p *pending[K, T]) pop(key K) (T, bool) {
+	pk, ok := p.items[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	item := pk.items[0]
+	if len(pk.items) == 1 {
+		delete(p.items, key)
+	} else {
+		pk.items = pk.items[1:]
+	}
+	return item, true
+}
+
+// live is reporting whether the given entry of keys is still having buffered items.
+func (p *pending[K, T]) live(k arrivedKey[K]) bool {
+	pk, ok := p.items[k.key]
+	return ok && pk.seq == k.seq
+}
+
+// compact is removing the stale entries of keys when they are making up more than half of it,
+// so the keys are not growing beyond twice the amount of buffered keys.
+func (p *pending[K, T]) compact() {
+	if len(p.keys) < 2*len(p.items)+1 {
+		return
+	}
+	keys := p.keys[:0]
+	for _, k := range p.keys {
+		if p.live(k) {
+			keys = append(keys, k)
+		}
+	}
+	clear(p.keys[len(keys):])
+	p.keys = keys
+}
+
+// all is returning all buffered items in the order their keys arrived.
+func (p *pending[K, T]) all() []T {
+	all := []T{}
+	for _, k := range p.keys {
+		if p.live(k) {
+			all = append(all, p.items[k.key].items...)
+		}
+	}
+	clear(p.items)
+	p.keys = nil
+	return all
+}
+
+// joiner is matching the items of both sides of a Join.
+type joiner[A, B any, K comparable] struct {
+	cfg      *joinConf
+	keyA     func(A) K
+	keyB     func(B) K
+	pendingA *pending[K, A]
+	pendingB *pending[K, B]
+	buffered int
+	itemChan chan JoinPair[A, B]
+	errChan  chan error
+}
+
+// run is matching the items until both sides are exhausted or the context is done. It returns the error
+// which stopped the join, if any.
+func (j *joiner[A, B, K]) run(ctx context.Context, chanA <-chan result[A], chanB <-chan result[B]) error {
+
+	for chanA != nil || chanB != nil {
+		var pair JoinPair[A, B]
+		var err error
+		var matched bool
+
+		select {
+		case res, ok := <-chanA:
+			if !ok {
+				chanA = nil
+				continue
+			}
+			if res.err != nil {
+				err = res.err
+				break
+			}
+
+			key := j.keyA(res.item)
+			if b, ok := j.pendingB.pop(key); ok {
+				pair, matched = JoinPair[A, B]{Left: res.item, Right: b, LeftOK: true, RightOK: true}, true
+				j.buffered--
+			} else {
+				j.pendingA.push(key, res.item)
+				j.buffered++
+			}
+
+		case res, ok := <-chanB:
+			if !ok {
+				chanB = nil
+				continue
+			}
+			if res.err != nil {
+				err = res.err
+				break
+			}
+
+			key := j.keyB(res.item)
+			if a, ok := j.pendingA.pop(key); ok {
+				pair, matched = JoinPair[A, B]{Left: a, Right: res.item, LeftOK: true, RightOK: true}, true
+				j.buffered--
+			} else {
+				j.pendingB.push(key, res.item)
+				j.buffered++
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+
+		if j.buffered > j.cfg.MaxBuffer {
+			err := fmt.Errorf("%w: more than %d unmatched items", ErrJoinBufferFull, j.cfg.MaxBuffer)
+			if !j.sendErr(ctx, err) {
+				return nil
+			}
+			return err
+		}
+
+		if err != nil && !j.sendErr(ctx, err) {
+			return nil
+		}
+
+		if matched && !j.send(ctx, pair) {
+			return nil
+		}
+	}
+
+	if j.cfg.Mode == JoinLeft || j.cfg.Mode == JoinOuter {
+		for _, a := range j.pendingA.all() {
+			if !j.send(ctx, JoinPair[A, B]{Left: a, LeftOK: true}) {
+				return nil
+			}
+		}
+	}
+
+	if j.cfg.Mode == JoinOuter {
+		for _, b := range j.pendingB.all() {
+			if !j.send(ctx, JoinPair[A, B]{Right: b, RightOK: true}) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// send is emitting a pair. It returns false if the context is done.
+func (j *joiner[A, B, K]) send(ctx context.Context, pair JoinPair[A, B]) bool {
+	select {
+	case j.itemChan <- pair:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendErr is emitting an error. It returns false if the context is done.
+func (j *joiner[A, B, K]) sendErr(ctx context.Context, err error) bool {
+	select {
+	case j.errChan <- err:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,209 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestZip(t *testing.T) {
+
+	inputs := []*closeRecorder{
+		{TypedIterator: FromSlice(ints)},
+		{TypedIterator: FromSlice([]int{10, 20, 30})},
+	}
+
+	iter := Zip[int, int, int](inputs[0], inputs[1], func(a, b int) (int, error) { return a + b, nil })
+	defer iter.Close()
+
+	items := []int{}
+	for {
+		item, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+
+	if want, got := []int{11, 22, 33}, items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+
+	// both inputs are closed when stopping at the shorter one
+	for i, in := range inputs {
+		if !in.closed {
+			t.Fatalf("Expected input %d to be closed", i)
+		}
+	}
+}
+
+func TestZipError(t *testing.T) {
+
+	errEven := errors.New("even")
+
+	// the failed item of the stream is not consuming an item of the other input
+	failing := NewStream(context.Background(), failingMapper, OrderedOpt(true), ContOnErrOpt(true))(&testIter{list: list})
+
+	iter := Zip[interface{}, int, string](failing, FromSlice(ints), func(a interface{}, b int) (string, error) {
+		if b%2 == 0 {
+			return "", errEven
+		}
+		return fmt.Sprintf("%d-%d", a.(data).input, b), nil
+	})
+	defer iter.Close()
+
+	items := []string{}
+	errs := 0
+	for {
+		item, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errEven) || errors.Is(err, errFive) {
+			errs++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+
+	if want, got := []string{"1-1", "3-3", "6-5", "8-7"}, items; !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+
+	if want, got := 5, errs; want != got {
+		t.Fatalf("Expected %d errors, got %d", want, got)
+	}
+}
+
+func TestZipClose(t *testing.T) {
+
+	sum := func(a, b int) (int, error) { return a + b, nil }
+
+	closeWhileBlocked(t, Zip[int, int, int](blockingInts(), FromSlice(ints), sum))
+	closeWhileBlocked(t, Zip[int, int, int](FromSlice(ints), blockingInts(), sum))
+}
+
+func TestJoin(t *testing.T) {
+
+	type record struct {
+		id   int
+		name string
+	}
+
+	left := []record{{1, "a"}, {2, "b"}, {3, "c"}, {5, "e"}}
+	right := []int{5, 4, 3, 1}
+
+	testCases := []struct {
+		mode JoinMode
+		want []string
+	}{
+		{mode: JoinInner, want: []string{"1:a-1", "3:c-3", "5:e-5"}},
+		{mode: JoinLeft, want: []string{"1:a-1", "2:b-", "3:c-3", "5:e-5"}},
+		{mode: JoinOuter, want: []string{"-4", "1:a-1", "2:b-", "3:c-3", "5:e-5"}},
+	}
+
+	for testnr, parms := range testCases {
+
+		iter := Join(context.Background(), FromSlice(left), FromSlice(right),
+			func(r record) int { return r.id }, func(id int) int { return id }, JoinModeOpt(parms.mode))
+		defer iter.Close()
+
+		items := []string{}
+		for {
+			pair, err := iter.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("test %d: %v", testnr, err)
+			}
+
+			item := ""
+			if pair.LeftOK {
+				item += fmt.Sprintf("%d:%s", pair.Left.id, pair.Left.name)
+			}
+			item += "-"
+			if pair.RightOK {
+				item += fmt.Sprint(pair.Right)
+			}
+			items = append(items, item)
+		}
+
+		slices.Sort(items)
+		if want, got := parms.want, items; !slices.Equal(want, got) {
+			t.Fatalf("test %d: Expected items %v, got %v", testnr, want, got)
+		}
+	}
+}
+
+func TestJoinBufferFull(t *testing.T) {
+
+	inputs := []*closeRecorder{
+		{TypedIterator: FromSlice(ints)},
+		{TypedIterator: FromSlice([]int{})},
+	}
+
+	id := func(i int) int { return i }
+	iter := Join[int, int](context.Background(), inputs[0], inputs[1], id, id, JoinBufferOpt(3))
+
+	var err error
+	for err == nil {
+		_, err = iter.Next()
+	}
+
+	if !errors.Is(err, ErrJoinBufferFull) {
+		t.Fatalf("Expected ErrJoinBufferFull, got %v", err)
+	}
+
+	if _, err := iter.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF: %v", err)
+	}
+
+	iter.Close()
+
+	for i, in := range inputs {
+		if !in.closed {
+			t.Fatalf("Expected input %d to be closed", i)
+		}
+	}
+}
+
+func TestJoinPending(t *testing.T) {
+
+	p := newPending[int, int]()
+
+	// the keys of matched items must not pile up
+	for i := 0; i < 1000; i++ {
+		p.push(i, i)
+		if _, ok := p.pop(i); !ok {
+			t.Fatalf("Expected item %d", i)
+		}
+	}
+
+	if len(p.keys) > 1 {
+		t.Fatalf("Expected at most 1 key, got %d", len(p.keys))
+	}
+
+	// a key pushed again after being popped is emitted at its new arrival position
+	p.push(1, 10)
+	p.push(2, 20)
+	p.push(1, 11)
+	p.pop(1)
+	p.pop(1)
+	p.push(3, 30)
+	p.push(1, 12)
+	p.push(3, 31)
+
+	if want, got := []int{20, 30, 31, 12}, p.all(); !slices.Equal(want, got) {
+		t.Fatalf("Expected items %v, got %v", want, got)
+	}
+}